@@ -0,0 +1,212 @@
+package bagit
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+const (
+	bagitVersion       = "1.0"
+	payloadDir         = "data"
+	declarationFile    = "bagit.txt"
+	bagInfoFile        = "bag-info.txt"
+	metadataExtension  = ".json"
+	metadataIndent     = "  "
+	sourceOrganization = "Ace Archive"
+	softwareAgent      = "artifact-action (https://github.com/acearchive/artifact-action)"
+)
+
+var ErrBagExists = errors.New("the bag directory already exists and is not empty")
+
+// algorithm is a checksum algorithm which the bag has a manifest for.
+type algorithm struct {
+	Name string
+	New  func() hash.Hash
+}
+
+// algorithms are the checksum algorithms which the bag has manifests for.
+var algorithms = []algorithm{
+	{Name: "sha256", New: sha256.New},
+	{Name: "sha512", New: sha512.New},
+}
+
+func (a algorithm) manifestFile() string {
+	return fmt.Sprintf("manifest-%s.txt", a.Name)
+}
+
+func (a algorithm) tagManifestFile() string {
+	return fmt.Sprintf("tagmanifest-%s.txt", a.Name)
+}
+
+// payloadFile is a file which belongs in the payload of the bag.
+type payloadFile struct {
+	// Path is the path of the file relative to the bag, with forward slashes.
+	Path string
+
+	// Cid is the CID of the file content, if it's an artifact file.
+	Cid *cid.Cid
+
+	// Content is the content of the file, if it's an artifact metadata file.
+	Content []byte
+}
+
+// payloadFiles returns every file in the payload of a bag of the most recent
+// version of each artifact in `artifacts`, sorted by path. Files whose name or
+// CID isn't valid are skipped with a warning.
+func payloadFiles(artifacts []parse.Artifact) ([]payloadFile, error) {
+	var files []payloadFile
+
+	for _, artifact := range parse.Latest(artifacts) {
+		metadata, err := json.MarshalIndent(artifact, "", metadataIndent)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, payloadFile{
+			Path:    path.Join(payloadDir, artifact.Slug+metadataExtension),
+			Cid:     nil,
+			Content: append(metadata, '\n'),
+		})
+
+		for _, file := range artifact.Entry.Objects(parse.FieldFiles) {
+			filename := file.String(parse.FieldFileFilename)
+
+			if filename == "" || filename != filepath.Base(filename) || strings.HasPrefix(filename, ".") {
+				logger.LogWarning(fmt.Sprintf("Skipping file with invalid name: %q", filename), logger.Slug(artifact.Slug), logger.Phase("bagit"))
+				continue
+			}
+
+			fileCid, err := cid.Parse(file.String(parse.FieldFileCid))
+			if err != nil {
+				logger.LogWarning(fmt.Sprintf("Skipping file with invalid CID: %s", filename), logger.Slug(artifact.Slug), logger.Phase("bagit"))
+				continue
+			}
+
+			files = append(files, payloadFile{
+				Path:    path.Join(payloadDir, artifact.Slug, filename),
+				Cid:     &fileCid,
+				Content: nil,
+			})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files, nil
+}
+
+// checksums is the checksum of a file with each algorithm, by the name of the
+// algorithm.
+type checksums map[string]string
+
+// checksumWriter computes the checksums of everything written to it.
+type checksumWriter struct {
+	hashes []hash.Hash
+	size   int64
+}
+
+func newChecksumWriter() *checksumWriter {
+	hashes := make([]hash.Hash, len(algorithms))
+
+	for i, alg := range algorithms {
+		hashes[i] = alg.New()
+	}
+
+	return &checksumWriter{hashes: hashes, size: 0}
+}
+
+func (w *checksumWriter) Write(p []byte) (int, error) {
+	for _, h := range w.hashes {
+		// Writing to a hash never returns an error.
+		h.Write(p) //nolint:errcheck
+	}
+
+	w.size += int64(len(p))
+
+	return len(p), nil
+}
+
+func (w *checksumWriter) Checksums() checksums {
+	sums := make(checksums, len(algorithms))
+
+	for i, alg := range algorithms {
+		sums[alg.Name] = hex.EncodeToString(w.hashes[i].Sum(nil))
+	}
+
+	return sums
+}
+
+// writeBagFile writes the file at the path `name` relative to the bag
+// directory `dir` with `write`, returning its checksums and size.
+func writeBagFile(dir, name string, write func(w io.Writer) error) (sums checksums, size int64, err error) {
+	filePath := filepath.Join(dir, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, 0, err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	checksumWriter := newChecksumWriter()
+
+	if err := write(io.MultiWriter(file, checksumWriter)); err != nil {
+		return nil, 0, err
+	}
+
+	return checksumWriter.Checksums(), checksumWriter.size, nil
+}
+
+// encodePath encodes a path for a manifest, where line breaks and percent
+// signs must be percent-encoded.
+func encodePath(name string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(name)
+}
+
+func decodePath(name string) string {
+	return strings.NewReplacer("%0D", "\r", "%0A", "\n", "%0d", "\r", "%0a", "\n", "%25", "%").Replace(name)
+}
+
+// writeManifest writes a manifest of the checksums of `files` with `alg`.
+func writeManifest(w io.Writer, alg algorithm, files map[string]checksums) error {
+	names := make([]string, 0, len(files))
+
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s  %s\n", files[name][alg.Name], encodePath(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
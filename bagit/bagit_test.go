@@ -0,0 +1,266 @@
+package bagit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/viper"
+)
+
+// testFiles is the content of each file in the fake IPFS node, by CID.
+var testFiles = map[string]string{
+	"bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku": "A digital scan\n",
+	"bafkreidgvpkjawlxz6sffxzwgooowe5yt7i6wsyg236mfoks77nywkptdq": "A transcript\n",
+}
+
+// TestMain serves `testFiles` from a fake IPFS HTTP API for the duration of
+// the tests. The IPFS client is created once and reused, so every test has to
+// share the same server.
+func TestMain(m *testing.M) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, exists := testFiles[strings.TrimPrefix(r.URL.Query().Get("arg"), "/ipfs/")]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v0/files/stat":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"Hash": %q, "Type": "file", "Size": %d}`, r.URL.Query().Get("arg"), len(content))
+		case "/api/v0/cat":
+			fmt.Fprint(w, content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		panic(err)
+	}
+
+	viper.Set("ipfs-api", fmt.Sprintf("/ip4/%s/tcp/%s", serverURL.Hostname(), serverURL.Port()))
+
+	code := m.Run()
+
+	server.Close()
+	os.Exit(code)
+}
+
+func testArtifact(slug string, day int, files map[string]string) parse.Artifact {
+	entryFiles := make([]interface{}, 0, len(files))
+
+	for filename, fileCid := range files {
+		entryFiles = append(entryFiles, testutil.File(filename, fileCid, ""))
+	}
+
+	return testutil.Artifact(slug, testutil.Date(day), parse.GenericEntry{string(parse.FieldFiles): entryFiles})
+}
+
+var testArtifacts = []parse.Artifact{
+	testArtifact("manifesto", 1, map[string]string{
+		"scan.pdf": "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+	}),
+	testArtifact("manifesto", 2, map[string]string{
+		"scan.pdf":        "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		"transcript.html": "bafkreidgvpkjawlxz6sffxzwgooowe5yt7i6wsyg236mfoks77nywkptdq",
+	}),
+	testArtifact("link-only", 1, nil),
+}
+
+// writeTestBag writes a bag of `testArtifacts` to a new directory.
+func writeTestBag(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "bag")
+
+	if err := Write(context.Background(), dir, testArtifacts); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestWriteAndValidate(t *testing.T) {
+	dir := writeTestBag(t)
+
+	for _, name := range []string{
+		"bagit.txt",
+		"bag-info.txt",
+		"manifest-sha256.txt",
+		"manifest-sha512.txt",
+		"tagmanifest-sha256.txt",
+		"tagmanifest-sha512.txt",
+		"data/manifesto.json",
+		"data/link-only.json",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("the bag is missing %s: %v", name, err)
+		}
+	}
+
+	for filePath, want := range map[string]string{
+		"data/manifesto/scan.pdf":        "A digital scan\n",
+		"data/manifesto/transcript.html": "A transcript\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(filePath)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", filePath, content, want)
+		}
+	}
+
+	bagInfo, err := os.ReadFile(filepath.Join(dir, bagInfoFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payloadSize := len("A digital scan\n") + len("A transcript\n")
+
+	for _, name := range []string{"manifesto.json", "link-only.json"} {
+		info, err := os.Stat(filepath.Join(dir, payloadDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		payloadSize += int(info.Size())
+	}
+
+	if want := fmt.Sprintf("Payload-Oxum: %d.4\n", payloadSize); !strings.Contains(string(bagInfo), want) {
+		t.Errorf("bag-info.txt doesn't contain %q:\n%s", want, bagInfo)
+	}
+
+	if err := Validate(dir, testArtifacts); err != nil {
+		t.Errorf("Validate() = %v, want no error", err)
+	}
+}
+
+func TestWriteToNonEmptyDir(t *testing.T) {
+	dir := writeTestBag(t)
+
+	if err := Write(context.Background(), dir, testArtifacts); !errors.Is(err, ErrBagExists) {
+		t.Errorf("Write() = %v, want %v", err, ErrBagExists)
+	}
+}
+
+func TestValidateFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		artifacts []parse.Artifact
+		modify    func(t *testing.T, dir string)
+		want      []error
+	}{
+		{
+			name:      "tampered file",
+			artifacts: testArtifacts,
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "data", "manifesto", "scan.pdf"), "Tampered\n")
+			},
+			want: []error{ErrChecksumMismatch, ErrPayloadOxum},
+		},
+		{
+			name:      "unlisted file",
+			artifacts: testArtifacts,
+			modify: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "data", "extra.txt"), "Extra\n")
+			},
+			want: []error{ErrUnlistedFile, ErrPayloadOxum, ErrNotInArtifacts},
+		},
+		{
+			name:      "missing file",
+			artifacts: testArtifacts,
+			modify: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "data", "manifesto", "transcript.html")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []error{ErrMissingFile, ErrPayloadOxum, ErrMissingArtifact},
+		},
+		{
+			name: "new artifact",
+			artifacts: append(
+				append([]parse.Artifact(nil), testArtifacts...),
+				testArtifact("new", 3, nil),
+			),
+			modify: func(t *testing.T, dir string) {},
+			want:   []error{ErrMissingArtifact},
+		},
+		{
+			name: "edited artifact",
+			artifacts: append(
+				append([]parse.Artifact(nil), testArtifacts...),
+				testArtifact("link-only", 3, nil),
+			),
+			modify: func(t *testing.T, dir string) {},
+			want:   []error{ErrStaleMetadata},
+		},
+		{
+			name:      "removed artifact",
+			artifacts: testArtifacts[:2],
+			modify:    func(t *testing.T, dir string) {},
+			want:      []error{ErrNotInArtifacts},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeTestBag(t)
+			test.modify(t, dir)
+
+			err := Validate(dir, test.artifacts)
+
+			if !errors.Is(err, ErrInvalidBag) {
+				t.Fatalf("Validate() = %v, want %v", err, ErrInvalidBag)
+			}
+
+			var bagErr InvalidBagError
+
+			if !errors.As(err, &bagErr) {
+				t.Fatalf("Validate() = %T, want InvalidBagError", err)
+			}
+
+			for _, want := range test.want {
+				found := false
+
+				for _, problem := range bagErr.Errors {
+					if errors.Is(problem, want) {
+						found = true
+						break
+					}
+				}
+
+				if !found {
+					t.Errorf("Validate() problems = %v, want %v", bagErr.Errors, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateNotABag(t *testing.T) {
+	if err := Validate(t.TempDir(), testArtifacts); !errors.Is(err, ErrNotABag) {
+		t.Errorf("Validate() = %v, want %v", err, ErrNotABag)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,302 @@
+package bagit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+var (
+	ErrInvalidBag       = errors.New("the bag is invalid")
+	ErrNotABag          = errors.New("not a bag")
+	ErrMissingManifest  = errors.New("missing manifest")
+	ErrInvalidManifest  = errors.New("invalid manifest line")
+	ErrMissingFile      = errors.New("file in the manifest is missing")
+	ErrChecksumMismatch = errors.New("checksum does not match")
+	ErrUnlistedFile     = errors.New("payload file is not in the manifest")
+	ErrPayloadOxum      = errors.New("Payload-Oxum does not match the payload")
+	ErrNotInArtifacts   = errors.New("payload file is not in the current artifacts")
+	ErrMissingArtifact  = errors.New("file in the current artifacts is missing from the bag")
+	ErrStaleMetadata    = errors.New("artifact metadata is out of date")
+)
+
+// InvalidBagError is returned by `Validate` when the bag is incomplete,
+// corrupt, or out of date. It wraps `ErrInvalidBag`.
+type InvalidBagError struct {
+	// Errors contains the reason for each problem with the bag.
+	Errors []error
+}
+
+func (e InvalidBagError) Error() string {
+	return ErrInvalidBag.Error()
+}
+
+func (e InvalidBagError) Unwrap() error {
+	return ErrInvalidBag
+}
+
+// fileChecksums computes the checksums and size of the file at `filePath`.
+func fileChecksums(filePath string) (sums checksums, size int64, err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	checksumWriter := newChecksumWriter()
+
+	if _, err := io.Copy(checksumWriter, file); err != nil {
+		return nil, 0, err
+	}
+
+	return checksumWriter.Checksums(), checksumWriter.size, nil
+}
+
+// readManifest returns the checksum of each file in the manifest `name` in
+// the bag directory `dir`, by the path of the file.
+func readManifest(dir, name string) (map[string]string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: %s:%d", ErrInvalidManifest, name, lineNumber)
+		}
+
+		manifest[decodePath(strings.TrimLeft(fields[1], " \t"))] = strings.ToLower(fields[0])
+	}
+
+	return manifest, scanner.Err()
+}
+
+// readPayloadOxum returns the Payload-Oxum from bag-info.txt, or an empty
+// string if there isn't one.
+func readPayloadOxum(dir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, bagInfoFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.HasPrefix(line, "Payload-Oxum:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Payload-Oxum:")), nil
+		}
+	}
+
+	return "", nil
+}
+
+// walkPayload returns the path of every file in the payload of the bag
+// directory `dir`, relative to `dir` with forward slashes.
+func walkPayload(dir string) ([]string, error) {
+	var paths []string
+
+	err := filepath.WalkDir(filepath.Join(dir, payloadDir), func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, filepath.ToSlash(relativePath))
+
+		return nil
+	})
+
+	return paths, err
+}
+
+// verifyManifest checks the files listed in the manifest `name` against their
+// checksums with `alg`, returning the manifest along with any problems. The
+// manifest is nil if it doesn't exist. The checksums of each file are
+// computed once and cached in `sums`.
+func verifyManifest(dir, name string, alg algorithm, sums map[string]checksums) (map[string]string, []error, error) {
+	manifest, err := readManifest(dir, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, []error{fmt.Errorf("%w: %s", ErrMissingManifest, name)}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	filePaths := make([]string, 0, len(manifest))
+
+	for filePath := range manifest {
+		filePaths = append(filePaths, filePath)
+	}
+
+	sort.Strings(filePaths)
+
+	var problems []error
+
+	for _, filePath := range filePaths {
+		expected := manifest[filePath]
+		actual, cached := sums[filePath]
+
+		if !cached {
+			actual, _, err = fileChecksums(filepath.Join(dir, filepath.FromSlash(filePath)))
+			if errors.Is(err, os.ErrNotExist) {
+				problems = append(problems, fmt.Errorf("%w: %s", ErrMissingFile, filePath))
+				continue
+			} else if err != nil {
+				return nil, nil, err
+			}
+
+			sums[filePath] = actual
+		}
+
+		if actual[alg.Name] != expected {
+			problems = append(problems, fmt.Errorf("%w: %s (%s)", ErrChecksumMismatch, filePath, alg.Name))
+		}
+	}
+
+	return manifest, problems, nil
+}
+
+// Validate checks the BagIt bag in the directory `dir` against the most
+// recent version of each artifact in `artifacts`. It checks that every file
+// in the manifests matches its checksum, that every payload file is in the
+// manifests, and that the bag contains the metadata and files of every
+// artifact and nothing else. If there are any problems, they're logged and an
+// `InvalidBagError` is returned.
+func Validate(dir string, artifacts []parse.Artifact) error {
+	startTime := time.Now()
+
+	if _, err := os.Stat(filepath.Join(dir, declarationFile)); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotABag, dir)
+	} else if err != nil {
+		return err
+	}
+
+	actualPaths, err := walkPayload(dir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		problems    []error
+		sums        = make(map[string]checksums, len(actualPaths))
+		payloadSize int64
+	)
+
+	for _, filePath := range actualPaths {
+		var size int64
+
+		if sums[filePath], size, err = fileChecksums(filepath.Join(dir, filepath.FromSlash(filePath))); err != nil {
+			return err
+		}
+
+		payloadSize += size
+	}
+
+	for _, alg := range algorithms {
+		manifest, manifestProblems, err := verifyManifest(dir, alg.manifestFile(), alg, sums)
+		if err != nil {
+			return err
+		}
+
+		problems = append(problems, manifestProblems...)
+
+		for _, filePath := range actualPaths {
+			if _, listed := manifest[filePath]; manifest != nil && !listed {
+				problems = append(problems, fmt.Errorf("%w: %s (%s)", ErrUnlistedFile, filePath, alg.Name))
+			}
+		}
+
+		// Tag manifests are optional, but they're checked if they exist.
+		if _, err := os.Stat(filepath.Join(dir, alg.tagManifestFile())); err == nil {
+			_, tagProblems, err := verifyManifest(dir, alg.tagManifestFile(), alg, make(map[string]checksums))
+			if err != nil {
+				return err
+			}
+
+			problems = append(problems, tagProblems...)
+		}
+	}
+
+	payloadOxum, err := readPayloadOxum(dir)
+	if err != nil {
+		return err
+	}
+
+	if actualOxum := fmt.Sprintf("%d.%d", payloadSize, len(actualPaths)); payloadOxum != "" && payloadOxum != actualOxum {
+		problems = append(problems, fmt.Errorf("%w: %s, but the payload is %s", ErrPayloadOxum, payloadOxum, actualOxum))
+	}
+
+	expectedFiles, err := payloadFiles(artifacts)
+	if err != nil {
+		return err
+	}
+
+	expectedPaths := make(map[string]struct{}, len(expectedFiles))
+
+	for _, file := range expectedFiles {
+		expectedPaths[file.Path] = struct{}{}
+
+		if _, exists := sums[file.Path]; !exists {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrMissingArtifact, file.Path))
+			continue
+		}
+
+		if file.Cid == nil {
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+			if err != nil {
+				return err
+			}
+
+			if !bytes.Equal(content, file.Content) {
+				problems = append(problems, fmt.Errorf("%w: %s", ErrStaleMetadata, file.Path))
+			}
+		}
+	}
+
+	for _, filePath := range actualPaths {
+		if _, expected := expectedPaths[filePath]; !expected {
+			problems = append(problems, fmt.Errorf("%w: %s", ErrNotInArtifacts, filePath))
+		}
+	}
+
+	if len(problems) > 0 {
+		logger.LogErrorGroup("Bag errors:", problems)
+		return InvalidBagError{Errors: problems}
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("The bag is valid and contains all %d current payload files", len(expectedFiles)),
+		logger.Phase("bagit"),
+		logger.Duration(time.Since(startTime)),
+	)
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package bagit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/acearchive/artifact-action/client"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+	files "github.com/ipfs/go-ipfs-files"
+	api "github.com/ipfs/go-ipfs-http-client"
+	"github.com/ipfs/interface-go-ipfs-core/path"
+)
+
+var ErrNotAFile = errors.New("the CID is not a UnixFS file")
+
+const baggingDateLayout = "2006-01-02"
+
+// tagFile is a file outside the payload which describes the bag.
+type tagFile struct {
+	name  string
+	write func(w io.Writer) error
+}
+
+// fetchFile writes the content of the UnixFS file with the CID `fileCid` to
+// `w`.
+func fetchFile(ctx context.Context, ipfsClient *api.HttpApi, fileCid cid.Cid, w io.Writer) error {
+	node, err := ipfsClient.Unixfs().Get(ctx, path.IpfsPath(fileCid))
+	if err != nil {
+		return err
+	}
+
+	defer node.Close()
+
+	file, ok := node.(files.File)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotAFile, fileCid.String())
+	}
+
+	_, err = io.Copy(w, file)
+
+	return err
+}
+
+// checkEmpty returns an error if `dir` exists and is not empty.
+func checkEmpty(dir string) error {
+	entries, err := os.ReadDir(dir)
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case len(entries) > 0:
+		return fmt.Errorf("%w: %s", ErrBagExists, dir)
+	default:
+		return nil
+	}
+}
+
+// writeBagInfo writes the bag-info.txt file, which describes the payload.
+func writeBagInfo(w io.Writer, payloadSize int64, payloadCount int, artifactCount int) error {
+	_, err := fmt.Fprintf(
+		w,
+		"Source-Organization: %s\n"+
+			"Bagging-Date: %s\n"+
+			"Payload-Oxum: %d.%d\n"+
+			"Bag-Software-Agent: %s\n"+
+			"External-Description: The most recent version of %d artifacts, with the metadata of each artifact in\n"+
+			"  data/<slug>.json and its files in data/<slug>/.\n",
+		sourceOrganization,
+		time.Now().UTC().Format(baggingDateLayout),
+		payloadSize,
+		payloadCount,
+		softwareAgent,
+		artifactCount,
+	)
+
+	return err
+}
+
+// Write writes a BagIt bag of the most recent version of each artifact in
+// `artifacts` to the directory `dir`, which must not exist or be empty. The
+// content of each file is fetched from the configured IPFS node.
+func Write(ctx context.Context, dir string, artifacts []parse.Artifact) error {
+	startTime := time.Now()
+
+	if err := checkEmpty(dir); err != nil {
+		return err
+	}
+
+	payload, err := payloadFiles(artifacts)
+	if err != nil {
+		return err
+	}
+
+	ipfsClientGuard, err := client.New()
+	if err != nil {
+		return err
+	}
+
+	ipfsClient := ipfsClientGuard.Lock()
+	defer ipfsClientGuard.Unlock()
+
+	var (
+		payloadSums   = make(map[string]checksums, len(payload))
+		payloadSize   int64
+		artifactCount int
+	)
+
+	for _, file := range payload {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		sums, size, err := writeBagFile(dir, file.Path, func(w io.Writer) error {
+			if file.Cid == nil {
+				_, err := w.Write(file.Content)
+				return err
+			}
+
+			return fetchFile(ctx, ipfsClient, *file.Cid, w)
+		})
+		if err != nil {
+			return err
+		}
+
+		if file.Cid == nil {
+			artifactCount++
+		} else {
+			logger.LogDebug(fmt.Sprintf("Fetched file: %s", file.Path), logger.Cid(*file.Cid), logger.Phase("bagit"))
+		}
+
+		payloadSums[file.Path] = sums
+		payloadSize += size
+	}
+
+	tagSums := make(map[string]checksums, 2+len(algorithms))
+
+	tagFiles := []tagFile{
+		{declarationFile, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "BagIt-Version: %s\nTag-File-Character-Encoding: UTF-8\n", bagitVersion)
+			return err
+		}},
+		{bagInfoFile, func(w io.Writer) error {
+			return writeBagInfo(w, payloadSize, len(payload), artifactCount)
+		}},
+	}
+
+	for _, alg := range algorithms {
+		alg := alg
+
+		tagFiles = append(tagFiles, tagFile{alg.manifestFile(), func(w io.Writer) error {
+			return writeManifest(w, alg, payloadSums)
+		}})
+	}
+
+	for _, file := range tagFiles {
+		if tagSums[file.name], _, err = writeBagFile(dir, file.name, file.write); err != nil {
+			return err
+		}
+	}
+
+	for _, alg := range algorithms {
+		var tagManifest bytes.Buffer
+
+		if err := writeManifest(&tagManifest, alg, tagSums); err != nil {
+			return err
+		}
+
+		if _, _, err := writeBagFile(dir, alg.tagManifestFile(), func(w io.Writer) error {
+			_, err := tagManifest.WriteTo(w)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("Wrote a bag of %d artifacts and %d files to %s", artifactCount, len(payload)-artifactCount, dir),
+		logger.Phase("bagit"),
+		logger.Duration(time.Since(startTime)),
+	)
+
+	return nil
+}
@@ -4,15 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 var (
-	ErrNotPinMode       = errors.New("these parameters are illegal when not pinning to IPFS")
-	ErrMissingPinParams = errors.New("missing mandatory parameters for pinning to IPFS")
-	ErrInvalidOutput    = errors.New("this is not a valid output type")
-	ErrInvalidMode      = errors.New("this is not a valid mode")
+	ErrMissingParams = errors.New("missing mandatory parameters")
+	ErrInvalidOutput = errors.New("this is not a valid output type")
+	ErrInvalidSource = errors.New("this is not a valid source")
+	ErrInvalidLog    = errors.New("this is not a valid logging option")
+	ErrNotFilterable = errors.New("this parameter can't be used in this mode")
+	ErrInvalidSort   = errors.New("this is not a valid sort order")
+	ErrInvalidFormat = errors.New("this is not a valid format")
 )
 
 type OperatingMode string
@@ -21,38 +25,151 @@ const (
 	ModeValidate OperatingMode = "validate"
 	ModeHistory  OperatingMode = "history"
 	ModePin      OperatingMode = "pin"
+	ModeDir      OperatingMode = "dir"
+	ModeExport   OperatingMode = "export"
 )
 
-var allModes = map[OperatingMode]struct{}{
-	ModeValidate: {},
-	ModeHistory:  {},
-	ModePin:      {},
-}
-
 type OutputType string
 
 const (
 	OutputArtifacts OutputType = "artifacts"
 	OutputCids      OutputType = "cids"
 	OutputRoot      OutputType = "root"
+	OutputSarif     OutputType = "sarif"
+	OutputJunit     OutputType = "junit"
+	OutputNdjson    OutputType = "ndjson"
+	OutputCsv       OutputType = "csv"
+	OutputTsv       OutputType = "tsv"
+	OutputTemplate  OutputType = "template"
+	OutputGrouped   OutputType = "grouped"
 	OutputSummary   OutputType = ""
 )
 
-var allOutputs = map[OutputType]struct{}{
-	OutputArtifacts: {},
-	OutputCids:      {},
-	OutputRoot:      {},
-	OutputSummary:   {},
+var allOutputs = []OutputType{
+	OutputArtifacts,
+	OutputCids,
+	OutputRoot,
+	OutputSarif,
+	OutputJunit,
+	OutputNdjson,
+	OutputCsv,
+	OutputTsv,
+	OutputTemplate,
+	OutputGrouped,
+	OutputSummary,
+}
+
+// Source is where artifact files are read from.
+type Source string
+
+const (
+	SourceTree    Source = "tree"
+	SourceHistory Source = "history"
+)
+
+var allSources = []Source{
+	SourceTree,
+	SourceHistory,
+}
+
+// SortOrder is the order artifacts are printed in.
+type SortOrder string
+
+const (
+	// SortSlug sorts by slug, then from least to most recent.
+	SortSlug SortOrder = "slug"
+
+	// SortDate sorts from least to most recent, then by slug.
+	SortDate SortOrder = "date"
+
+	// SortNewest sorts from most to least recent, then by slug.
+	SortNewest SortOrder = "newest"
+
+	// SortTitle sorts by the sort key of the title, then by slug.
+	SortTitle SortOrder = "title"
+
+	// SortNone keeps the order the artifacts were found in, which lets
+	// ndjson output be streamed.
+	SortNone SortOrder = "none"
+)
+
+var allSortOrders = []SortOrder{
+	SortSlug,
+	SortDate,
+	SortNewest,
+	SortTitle,
+	SortNone,
+}
+
+// StatsFormatType is the format of the statistics report.
+type StatsFormatType string
+
+const (
+	StatsFormatMarkdown StatsFormatType = "markdown"
+	StatsFormatJSON     StatsFormatType = "json"
+)
+
+// RedirectFormatType is the format of exported redirect rules.
+type RedirectFormatType string
+
+const (
+	RedirectFormatNetlify    RedirectFormatType = "netlify"
+	RedirectFormatCloudflare RedirectFormatType = "cloudflare"
+	RedirectFormatNginx      RedirectFormatType = "nginx"
+)
+
+var allRedirectFormats = []RedirectFormatType{RedirectFormatNetlify, RedirectFormatCloudflare, RedirectFormatNginx}
+
+type LogFormatType string
+
+const (
+	LogFormatText LogFormatType = "text"
+	LogFormatJSON LogFormatType = "json"
+)
+
+type LogLevelType string
+
+const (
+	LogLevelDebug LogLevelType = "debug"
+	LogLevelInfo  LogLevelType = "info"
+	LogLevelWarn  LogLevelType = "warn"
+	LogLevelError LogLevelType = "error"
+)
+
+var allLogLevels = []LogLevelType{
+	LogLevelDebug,
+	LogLevelInfo,
+	LogLevelWarn,
+	LogLevelError,
 }
 
+// DefaultOutputThreshold is the size in bytes above which action outputs are
+// written to files instead. GitHub limits each output to 1 MiB.
+const DefaultOutputThreshold = 512 * 1024
+
 const (
-	DefaultMode = ModeValidate
-	DefaultPath = "artifacts/"
+	DefaultMode      = ModeValidate
+	DefaultPath      = "artifacts/"
+	DefaultSource    = SourceHistory
+	DefaultLogFormat = LogFormatText
+	DefaultLogLevel  = LogLevelInfo
+	DefaultGateway   = "https://ipfs.io"
+	DefaultSort      = SortSlug
+	DefaultFeedTitle = "Ace Archive"
+
+	DefaultServeAddr      = ":8080"
+	DefaultRepositoryName = "Ace Archive"
+	DefaultOAIIdentifier  = "acearchive.lgbt"
 )
 
 func init() {
 	viper.SetDefault("mode", string(DefaultMode))
 	viper.SetDefault("path", string(DefaultPath))
+	viper.SetDefault("log-format", string(DefaultLogFormat))
+	viper.SetDefault("log-level", string(DefaultLogLevel))
+	viper.SetDefault("output-threshold", DefaultOutputThreshold)
+	viper.SetDefault("gateway", DefaultGateway)
+	viper.SetDefault("sort", string(DefaultSort))
 
 	if err := viper.BindEnv("repo", "GITHUB_WORKSPACE"); err != nil {
 		panic(err)
@@ -81,6 +198,46 @@ func init() {
 	if err := viper.BindEnv("dry-run", "INPUT_DRY-RUN"); err != nil {
 		panic(err)
 	}
+
+	if err := viper.BindEnv("timeout", "INPUT_TIMEOUT"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("output-dir", "INPUT_OUTPUT-DIR"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("output-threshold", "INPUT_OUTPUT-THRESHOLD"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("output-files", "INPUT_OUTPUT-FILES"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("sarif", "INPUT_SARIF"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("where", "INPUT_WHERE"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("latest-only", "INPUT_LATEST-ONLY"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("sort", "INPUT_SORT"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("log-format", "INPUT_LOG-FORMAT"); err != nil {
+		panic(err)
+	}
+
+	if err := viper.BindEnv("log-level", "INPUT_LOG-LEVEL"); err != nil {
+		panic(err)
+	}
 }
 
 func Repo() string {
@@ -107,76 +264,405 @@ func PinToken() string {
 	return viper.GetString("pin-token")
 }
 
+// Output is the output type to print. Passing a template implies the
+// template output type.
 func Output() OutputType {
+	if viper.GetString("output") == "" && Template() != "" {
+		return OutputTemplate
+	}
+
 	return OutputType(viper.GetString("output"))
 }
 
+// Template is the path of the text/template file to render with the template
+// output type.
+func Template() string {
+	return viper.GetString("template")
+}
+
+// Gateway is the base URL of the IPFS gateway to use in URLs for files.
+func Gateway() string {
+	return viper.GetString("gateway")
+}
+
+// OutputFile is the path of the file to print the selected output to instead
+// of stdout.
+func OutputFile() string {
+	return viper.GetString("output-file")
+}
+
+// OutputDir is the directory that action outputs are written to when they're
+// written to files.
+func OutputDir() string {
+	return viper.GetString("output-dir")
+}
+
+// OutputThreshold is the size in bytes above which action outputs are written
+// to files.
+func OutputThreshold() int {
+	return viper.GetInt("output-threshold")
+}
+
+// OutputFiles is whether action outputs are always written to files.
+func OutputFiles() bool {
+	return viper.GetBool("output-files")
+}
+
+// Sarif is whether to write a SARIF report of validation failures to a file
+// when running as a GitHub Action.
+func Sarif() bool {
+	return viper.GetBool("sarif")
+}
+
+// Table is the table to print when printing CSV or TSV.
+func Table() string {
+	return viper.GetString("table")
+}
+
+// Columns is the columns of the table to print when printing CSV or TSV, or
+// empty for the default columns.
+func Columns() []string {
+	return viper.GetStringSlice("columns")
+}
+
+// Streaming is whether artifacts are printed as soon as they're parsed instead
+// of all at once at the end.
+func Streaming() bool {
+	return !Action() && Output() == OutputNdjson && !LatestOnly() && Sort() == SortNone
+}
+
 func Action() bool {
 	return viper.GetBool("action")
 }
 
+func LogFormat() LogFormatType {
+	return LogFormatType(viper.GetString("log-format"))
+}
+
+func LogLevel() LogLevelType {
+	return LogLevelType(viper.GetString("log-level"))
+}
+
 func DryRun() bool {
 	return viper.GetBool("dry-run")
 }
 
+// Timeout is the maximum duration of the whole run. Zero means no timeout.
+func Timeout() time.Duration {
+	return viper.GetDuration("timeout")
+}
+
+// HistoryTimeout is the maximum duration of traversing the git history. Zero
+// means no timeout.
+func HistoryTimeout() time.Duration {
+	return viper.GetDuration("history-timeout")
+}
+
+// BuildTimeout is the maximum duration of building the root directory. Zero
+// means no timeout.
+func BuildTimeout() time.Duration {
+	return viper.GetDuration("build-timeout")
+}
+
+// PinTimeout is the maximum duration of pinning files to the pinning service.
+// Zero means no timeout.
+func PinTimeout() time.Duration {
+	return viper.GetDuration("pin-timeout")
+}
+
+func ExportSource() Source {
+	return Source(viper.GetString("source"))
+}
+
+func ExportFormat() string {
+	return viper.GetString("format")
+}
+
+func ExportFile() string {
+	return viper.GetString("file")
+}
+
+// StatsFormat is the format of the statistics report.
+func StatsFormat() StatsFormatType {
+	return StatsFormatType(viper.GetString("format"))
+}
+
+// RedirectFormat is the format of exported redirect rules.
+func RedirectFormat() RedirectFormatType {
+	return RedirectFormatType(viper.GetString("redirect-format"))
+}
+
+// RedirectFormats returns the names of all the valid redirect formats.
+func RedirectFormats() []string {
+	names := make([]string, len(allRedirectFormats))
+
+	for i, format := range allRedirectFormats {
+		names[i] = string(format)
+	}
+
+	return names
+}
+
+// SiteURL is the base URL of the pages for artifacts on the website, which
+// the slug of each artifact is appended to.
+func SiteURL() string {
+	return viper.GetString("site-url")
+}
+
+// FeedTitle is the title of exported feeds.
+func FeedTitle() string {
+	return viper.GetString("feed-title")
+}
+
+// FeedUpdates is whether exported feeds include an entry each time files are
+// added to an artifact.
+func FeedUpdates() bool {
+	return viper.GetBool("updates")
+}
+
+// FeedLimit is the maximum number of entries in exported feeds, or 0 for no
+// limit.
+func FeedLimit() int {
+	return viper.GetInt("limit")
+}
+
+// ServeAddr is the TCP address for the server to listen on.
+func ServeAddr() string {
+	return viper.GetString("addr")
+}
+
+// RepositoryName is the human-readable name of the OAI-PMH repository.
+func RepositoryName() string {
+	return viper.GetString("repository-name")
+}
+
+// OAIIdentifier is the namespace of OAI identifiers, which is usually the
+// domain name of the site.
+func OAIIdentifier() string {
+	return viper.GetString("oai-identifier")
+}
+
+// AdminEmail is the email address of the administrator of the OAI-PMH
+// repository.
+func AdminEmail() string {
+	return viper.GetString("admin-email")
+}
+
+// PageSize is the maximum number of records in a single OAI-PMH response.
+func PageSize() int {
+	return viper.GetInt("page-size")
+}
+
+// ExportDir is the directory to write one export file per artifact to, or
+// empty to write a single file.
+func ExportDir() string {
+	return viper.GetString("dir")
+}
+
+// BagValidation is whether to validate an existing bag instead of writing a
+// new one.
+func BagValidation() bool {
+	return viper.GetBool("validate")
+}
+
+// Where is the filter expression which selects which artifacts to include, or
+// empty to include every artifact.
+func Where() string {
+	return viper.GetString("where")
+}
+
+// Sort is the order to print artifacts in.
+func Sort() SortOrder {
+	return SortOrder(viper.GetString("sort"))
+}
+
+// LatestOnly is whether to only include the most recent version of each
+// artifact in the history.
+func LatestOnly() bool {
+	return viper.GetBool("latest-only")
+}
+
+// OutputTypes returns the names of all the valid output types.
+func OutputTypes() []string {
+	names := make([]string, 0, len(allOutputs))
+
+	for _, output := range allOutputs {
+		if output != OutputSummary {
+			names = append(names, string(output))
+		}
+	}
+
+	return names
+}
+
+// LogLevels returns the names of all the valid log levels.
+func LogLevels() []string {
+	names := make([]string, len(allLogLevels))
+
+	for i, level := range allLogLevels {
+		names[i] = string(level)
+	}
+
+	return names
+}
+
+// SortOrders returns the names of all the valid sort orders.
+func SortOrders() []string {
+	names := make([]string, len(allSortOrders))
+
+	for i, order := range allSortOrders {
+		names[i] = string(order)
+	}
+
+	return names
+}
+
+// Sources returns the names of all the valid sources.
+func Sources() []string {
+	names := make([]string, len(allSources))
+
+	for i, source := range allSources {
+		names[i] = string(source)
+	}
+
+	return names
+}
+
 func StringifyInput(input string) string {
 	if Action() {
 		return fmt.Sprintf("`%s`", input)
 	}
 
-	return fmt.Sprintf("`--%s", input)
+	return fmt.Sprintf("`--%s`", input)
 }
 
-func ValidateParams() error {
-	if _, isValid := allOutputs[Output()]; !isValid {
-		return fmt.Errorf("%w: %s", ErrInvalidOutput, Output())
+func requireParams(params ...string) error {
+	var missingParams []string
+
+	for _, param := range params {
+		if viper.GetString(param) == "" {
+			missingParams = append(missingParams, StringifyInput(param))
+		}
 	}
 
-	if _, isValid := allModes[Mode()]; !isValid {
-		return fmt.Errorf("%w: %s", ErrInvalidMode, Mode())
+	if len(missingParams) == 0 {
+		return nil
 	}
 
-	hasIpfsAPI := viper.GetString("ipfs-api") != ""
-	hasPinEndpoint := viper.GetString("pin-endpoint") != ""
-	hasPinToken := viper.GetString("pin-token") != ""
-	hasAnyPinParams := hasIpfsAPI || hasPinEndpoint || hasPinToken
-	hasAllPinParams := hasIpfsAPI && hasPinEndpoint && hasPinToken
+	return fmt.Errorf("%w: %s", ErrMissingParams, strings.Join(missingParams, ", "))
+}
 
-	if Mode() == ModePin && !hasAllPinParams {
-		missingParams := make([]string, 0, 3)
+func ValidateOutput() error {
+	if Output() == OutputTemplate {
+		return requireParams("template")
+	}
 
-		if !hasIpfsAPI {
-			missingParams = append(missingParams, StringifyInput("ipfs-api"))
+	for _, output := range allOutputs {
+		if Output() == output {
+			return nil
 		}
+	}
 
-		if !hasPinEndpoint {
-			missingParams = append(missingParams, StringifyInput("pin-endpoint"))
+	return fmt.Errorf("%w: %s", ErrInvalidOutput, Output())
+}
+
+func ValidateSort() error {
+	for _, order := range allSortOrders {
+		if Sort() == order {
+			return nil
 		}
+	}
 
-		if !hasPinToken {
-			missingParams = append(missingParams, StringifyInput("pin-token"))
+	return fmt.Errorf("%w: %s", ErrInvalidSort, Sort())
+}
+
+func ValidateRedirectFormat() error {
+	for _, format := range allRedirectFormats {
+		if RedirectFormat() == format {
+			return nil
 		}
+	}
 
-		return fmt.Errorf("%w: %s", ErrMissingPinParams, strings.Join(missingParams, ", "))
+	return fmt.Errorf("%w: %s", ErrInvalidFormat, RedirectFormat())
+}
+
+func ValidateStatsFormat() error {
+	if format := StatsFormat(); format != StatsFormatMarkdown && format != StatsFormatJSON {
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, format)
 	}
 
-	if Mode() != ModePin && hasAnyPinParams {
-		illegalParams := make([]string, 0, 3)
+	return nil
+}
 
-		if hasIpfsAPI {
-			illegalParams = append(illegalParams, StringifyInput("ipfs-api"))
+func ValidateSource() error {
+	for _, source := range allSources {
+		if ExportSource() == source {
+			return nil
 		}
+	}
 
-		if hasPinEndpoint {
-			illegalParams = append(illegalParams, StringifyInput("pin-endpoint"))
-		}
+	return fmt.Errorf("%w: %s", ErrInvalidSource, ExportSource())
+}
 
-		if hasPinToken {
-			illegalParams = append(illegalParams, StringifyInput("pin-token"))
+func ValidateLogging() error {
+	if format := LogFormat(); format != LogFormatText && format != LogFormatJSON {
+		return fmt.Errorf("%w: %s", ErrInvalidLog, format)
+	}
+
+	for _, level := range allLogLevels {
+		if LogLevel() == level {
+			return nil
 		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrInvalidLog, LogLevel())
+}
+
+func ValidateDirParams() error {
+	return requireParams("ipfs-api")
+}
+
+func ValidatePinParams() error {
+	return requireParams("ipfs-api", "pin-endpoint", "pin-token")
+}
 
-		return fmt.Errorf("%w: %s", ErrNotPinMode, strings.Join(illegalParams, ", "))
+// ValidateFullHistory checks that --latest-only isn't set, for modes which
+// must see every version of every artifact. Pinning only the latest versions
+// would silently stop pinning the files in older versions.
+func ValidateFullHistory() error {
+	if LatestOnly() {
+		return fmt.Errorf("%w: %s", ErrNotFilterable, StringifyInput("latest-only"))
 	}
 
 	return nil
 }
+
+func ValidateSiteParams() error {
+	return requireParams("site-url")
+}
+
+func ValidateOAIParams() error {
+	return requireParams("admin-email", "oai-identifier")
+}
+
+// ValidateBagParams checks that a bag directory was given, along with an IPFS
+// node to fetch files from when writing a new bag.
+func ValidateBagParams() error {
+	if BagValidation() {
+		return requireParams("dir")
+	}
+
+	return requireParams("dir", "ipfs-api")
+}
+
+// ValidateExportDirParams checks that an export directory was given, for
+// export formats which are made up of several files.
+func ValidateExportDirParams() error {
+	return requireParams("dir")
+}
+
+// ValidateExportFileParams checks that an export file was given, for export
+// formats which can't be written to stdout.
+func ValidateExportFileParams() error {
+	return requireParams("file")
+}
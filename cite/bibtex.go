@@ -0,0 +1,106 @@
+package cite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+)
+
+type bibtexField struct {
+	name  string
+	value string
+}
+
+// BibTeX cites artifacts as `@misc` entries.
+var BibTeX = Format{
+	Extension: "bib",
+	Write:     writeBibTeX,
+}
+
+func bibtexFields(record Record) []bibtexField {
+	fields := []bibtexField{
+		{name: "title", value: bibtexEscaper.Replace(record.Title)},
+	}
+
+	if len(record.Authors) > 0 {
+		authors := make([]string, len(record.Authors))
+
+		// Wrapping each name in braces keeps BibTeX from mangling the names
+		// of organizations.
+		for i, author := range record.Authors {
+			authors[i] = fmt.Sprintf("{%s}", bibtexEscaper.Replace(author))
+		}
+
+		fields = append(fields, bibtexField{name: "author", value: strings.Join(authors, " and ")})
+	}
+
+	if record.HasYears {
+		fields = append(fields, bibtexField{name: "year", value: fmt.Sprint(record.FromYear)})
+		fields = append(fields, bibtexField{name: "date", value: strings.ReplaceAll(record.Years(), "-", "/")})
+	}
+
+	if record.Description != "" {
+		fields = append(fields, bibtexField{name: "abstract", value: bibtexEscaper.Replace(record.Description)})
+	}
+
+	if record.URL != "" {
+		fields = append(fields, bibtexField{name: "url", value: record.URL})
+		fields = append(fields, bibtexField{name: "howpublished", value: fmt.Sprintf(`\url{%s}`, record.URL)})
+	}
+
+	if notes := fileNotes(record.Files); len(notes) > 0 {
+		escapedNotes := make([]string, len(notes))
+
+		for i, note := range notes {
+			escapedNotes[i] = bibtexEscaper.Replace(note)
+		}
+
+		fields = append(fields, bibtexField{name: "note", value: "Files: " + strings.Join(escapedNotes, "; ")})
+	}
+
+	fields = append(fields, bibtexField{name: "organization", value: ArchiveName})
+
+	return fields
+}
+
+func writeBibTeX(w io.Writer, records []Record) error {
+	var builder strings.Builder
+
+	for i, record := range records {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		fmt.Fprintf(&builder, "@misc{%s,\n", record.Key)
+
+		fields := bibtexFields(record)
+
+		for fieldIndex, field := range fields {
+			separator := ","
+			if fieldIndex == len(fields)-1 {
+				separator = ""
+			}
+
+			fmt.Fprintf(&builder, "  %s = {%s}%s\n", field.name, field.value, separator)
+		}
+
+		builder.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
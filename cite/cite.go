@@ -0,0 +1,155 @@
+package cite
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/acearchive/artifact-action/gateway"
+	"github.com/acearchive/artifact-action/markdown"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// ArchiveName is the name of the archive that artifacts are cited as being
+// part of.
+const ArchiveName = "Ace Archive"
+
+// Options configures how artifacts are cited.
+type Options struct {
+	// SiteURL is the base URL of artifact pages, which the slug of each
+	// artifact is appended to. If it's empty, artifacts are cited by their
+	// first link instead.
+	SiteURL string
+
+	// Gateway is the base URL of the IPFS gateway to use in URLs for files.
+	Gateway string
+}
+
+// File is a file in a citation.
+type File struct {
+	Name      string
+	Filename  string
+	MediaType string
+	URL       string
+}
+
+// Record is the citation of an artifact. It's built from a `GenericEntry`
+// so that past schema versions can still be cited.
+type Record struct {
+	// Key is the citation key, which is the slug of the artifact so that it's
+	// stable.
+	Key         string
+	Title       string
+	Authors     []string
+	FromYear    int
+	ToYear      int
+	HasYears    bool
+	Description string
+	URL         string
+	Links       []string
+	Files       []File
+}
+
+// Format is a citation format.
+type Format struct {
+	// Extension is the file extension for the format, without the dot.
+	Extension string
+
+	// Write writes the citations to `w`.
+	Write func(w io.Writer, records []Record) error
+}
+
+// Years returns the years of the artifact, like `1972` or `1972-1975`.
+func (r Record) Years() string {
+	if !r.HasYears {
+		return ""
+	}
+
+	if r.ToYear == 0 || r.ToYear == r.FromYear {
+		return fmt.Sprint(r.FromYear)
+	}
+
+	return fmt.Sprintf("%d-%d", r.FromYear, r.ToYear)
+}
+
+// NewRecord returns the citation of `artifact`.
+func NewRecord(artifact parse.Artifact, opts Options) Record {
+	entry := artifact.Entry
+	fromYear, hasYears := entry.Int(parse.FieldFromYear)
+	toYear, _ := entry.Int(parse.FieldToYear)
+
+	record := Record{
+		Key:         artifact.Slug,
+		Title:       artifact.TitlePlain,
+		Authors:     entry.Strings(parse.FieldPeople),
+		FromYear:    fromYear,
+		ToYear:      toYear,
+		HasYears:    hasYears,
+		Description: markdown.Plain(strings.TrimSpace(entry.String(parse.FieldDescription))),
+		URL:         "",
+		Links:       nil,
+		Files:       nil,
+	}
+
+	for _, link := range entry.Objects(parse.FieldLinks) {
+		if url := link.String(parse.FieldLinkURL); url != "" {
+			record.Links = append(record.Links, url)
+		}
+	}
+
+	for _, file := range entry.Objects(parse.FieldFiles) {
+		filename := file.String(parse.FieldFileFilename)
+
+		// Files with invalid CIDs are cited without a URL.
+		fileURL, _ := gateway.URL(opts.Gateway, file.String(parse.FieldFileCid), filename)
+
+		record.Files = append(record.Files, File{
+			Name:      file.String(parse.FieldFileName),
+			Filename:  filename,
+			MediaType: file.String(parse.FieldFileMediaType),
+			URL:       fileURL,
+		})
+	}
+
+	switch {
+	case opts.SiteURL != "":
+		record.URL = fmt.Sprintf("%s/%s", strings.TrimSuffix(opts.SiteURL, "/"), artifact.Slug)
+	case len(record.Links) > 0:
+		record.URL = record.Links[0]
+	}
+
+	return record
+}
+
+// NewRecords returns the citation of each artifact in `artifacts`, sorted by
+// citation key. Only the most recent version of each artifact is cited.
+func NewRecords(artifacts []parse.Artifact, opts Options) []Record {
+	latest := parse.Latest(artifacts)
+	records := make([]Record, len(latest))
+
+	for i, artifact := range latest {
+		records[i] = NewRecord(artifact, opts)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key < records[j].Key
+	})
+
+	return records
+}
+
+// fileNotes returns a note listing each file and its URL.
+func fileNotes(files []File) []string {
+	notes := make([]string, 0, len(files))
+
+	for _, file := range files {
+		if file.URL == "" {
+			notes = append(notes, file.Name)
+		} else {
+			notes = append(notes, fmt.Sprintf("%s: %s", file.Name, file.URL))
+		}
+	}
+
+	return notes
+}
@@ -0,0 +1,170 @@
+package cite
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const (
+	testCid     = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	testFileURL = "https://gateway.example.com/ipfs/" + testCid + "?filename=scan.pdf"
+	testURL     = "https://example.com/artifacts/orlando-the-asexual-manifesto"
+)
+
+var testOptions = Options{SiteURL: "https://example.com/artifacts/", Gateway: "https://gateway.example.com"}
+
+func testRecords() []Record {
+	manifesto := testutil.Artifact("orlando-the-asexual-manifesto", testutil.Date(1), parse.GenericEntry{
+		string(parse.FieldTitle):       "*The Asexual Manifesto*",
+		string(parse.FieldDescription): "A paper by the _New York Radical Feminists_, 100% {ace} & more\n",
+		string(parse.FieldFiles): []interface{}{
+			testutil.File("scan.pdf", testCid, "application/pdf"),
+		},
+		string(parse.FieldLinks): []interface{}{
+			map[string]interface{}{"name": "Website", "url": "https://example.org/manifesto"},
+		},
+		string(parse.FieldPeople):   []interface{}{"Lisa Orlando", "New York Radical Feminists"},
+		string(parse.FieldFromYear): 1972,
+		string(parse.FieldToYear):   1975,
+	})
+
+	// An artifact without people, years, or files leaves those fields out.
+	zine := testutil.Artifact("zine", testutil.Date(1), parse.GenericEntry{
+		string(parse.FieldFromYear): nil,
+	})
+
+	return NewRecords([]parse.Artifact{zine, manifesto}, testOptions)
+}
+
+func TestWriteBibTeX(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := BibTeX.Write(&buf, testRecords()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `@misc{orlando-the-asexual-manifesto,
+  title = {The Asexual Manifesto},
+  author = {{Lisa Orlando} and {New York Radical Feminists}},
+  year = {1972},
+  date = {1972/1975},
+  abstract = {A paper by the New York Radical Feminists, 100\% \{ace\} \& more},
+  url = {` + testURL + `},
+  howpublished = {\url{` + testURL + `}},
+  note = {Files: scan.pdf: ` + testFileURL + `},
+  organization = {Ace Archive}
+}
+
+@misc{zine,
+  title = {The zine},
+  abstract = {A description of zine},
+  url = {https://example.com/artifacts/zine},
+  howpublished = {\url{https://example.com/artifacts/zine}},
+  organization = {Ace Archive}
+}
+`
+
+	if got := buf.String(); got != want {
+		t.Errorf("BibTeX =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteCSLJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := CSLJSON.Write(&buf, testRecords()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]interface{}
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]interface{}{
+		{
+			"id":    "orlando-the-asexual-manifesto",
+			"type":  "document",
+			"title": "The Asexual Manifesto",
+			"author": []interface{}{
+				map[string]interface{}{"literal": "Lisa Orlando"},
+				map[string]interface{}{"literal": "New York Radical Feminists"},
+			},
+			"issued":   map[string]interface{}{"date-parts": []interface{}{[]interface{}{1972.0}, []interface{}{1975.0}}},
+			"abstract": "A paper by the New York Radical Feminists, 100% {ace} & more",
+			"URL":      testURL,
+			"archive":  "Ace Archive",
+			"note":     "Files: scan.pdf: " + testFileURL,
+		},
+		{
+			"id":       "zine",
+			"type":     "document",
+			"title":    "The zine",
+			"abstract": "A description of zine",
+			"URL":      "https://example.com/artifacts/zine",
+			"archive":  "Ace Archive",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CSL-JSON =\n%s", buf.String())
+	}
+}
+
+func TestWriteRIS(t *testing.T) {
+	// The end tag has a trailing space.
+	const risEnd = "ER  - "
+
+	var buf bytes.Buffer
+
+	if err := RIS.Write(&buf, testRecords()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `TY  - GEN
+ID  - orlando-the-asexual-manifesto
+TI  - The Asexual Manifesto
+AU  - Lisa Orlando
+AU  - New York Radical Feminists
+PY  - 1972
+Y2  - 1975
+AB  - A paper by the New York Radical Feminists, 100% {ace} & more
+UR  - ` + testURL + `
+UR  - https://example.org/manifesto
+L1  - ` + testFileURL + `
+N1  - scan.pdf: ` + testFileURL + `
+DB  - Ace Archive
+` + risEnd + `
+
+TY  - GEN
+ID  - zine
+TI  - The zine
+AB  - A description of zine
+UR  - https://example.com/artifacts/zine
+DB  - Ace Archive
+` + risEnd + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("RIS =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNewRecordWithoutSiteURL(t *testing.T) {
+	artifact := testutil.Artifact("zine", testutil.Date(1), parse.GenericEntry{
+		string(parse.FieldLinks): []interface{}{
+			map[string]interface{}{"name": "Website", "url": "https://example.org/zine"},
+		},
+	})
+
+	record := NewRecord(artifact, Options{SiteURL: "", Gateway: testOptions.Gateway})
+
+	if record.URL != "https://example.org/zine" {
+		t.Errorf("URL = %q, want the first link", record.URL)
+	}
+}
@@ -0,0 +1,81 @@
+package cite
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+type cslName struct {
+	Literal string `json:"literal"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+type cslItem struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	Author   []cslName `json:"author,omitempty"`
+	Issued   *cslDate  `json:"issued,omitempty"`
+	Abstract string    `json:"abstract,omitempty"`
+	URL      string    `json:"URL,omitempty"`
+	Archive  string    `json:"archive"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// CSLJSON cites artifacts as CSL-JSON items, which is what Zotero and
+// citeproc use.
+var CSLJSON = Format{
+	Extension: "json",
+	Write:     writeCSLJSON,
+}
+
+func newCSLItem(record Record) cslItem {
+	item := cslItem{
+		ID:       record.Key,
+		Type:     "document",
+		Title:    record.Title,
+		Author:   make([]cslName, len(record.Authors)),
+		Issued:   nil,
+		Abstract: record.Description,
+		URL:      record.URL,
+		Archive:  ArchiveName,
+		Note:     "",
+	}
+
+	// People can be organizations, so we don't try to split names into
+	// given and family names.
+	for i, author := range record.Authors {
+		item.Author[i] = cslName{Literal: author}
+	}
+
+	if record.HasYears {
+		item.Issued = &cslDate{DateParts: [][]int{{record.FromYear}}}
+
+		if record.ToYear != 0 && record.ToYear != record.FromYear {
+			item.Issued.DateParts = append(item.Issued.DateParts, []int{record.ToYear})
+		}
+	}
+
+	if notes := fileNotes(record.Files); len(notes) > 0 {
+		item.Note = "Files: " + strings.Join(notes, "; ")
+	}
+
+	return item
+}
+
+func writeCSLJSON(w io.Writer, records []Record) error {
+	items := make([]cslItem, len(records))
+
+	for i, record := range records {
+		items[i] = newCSLItem(record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(items)
+}
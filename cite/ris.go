@@ -0,0 +1,72 @@
+package cite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RIS cites artifacts as `GEN` references.
+var RIS = Format{
+	Extension: "ris",
+	Write:     writeRIS,
+}
+
+func writeRISTag(builder *strings.Builder, tag, value string) {
+	// Values can't span lines.
+	value = strings.Join(strings.Fields(value), " ")
+
+	if value != "" {
+		fmt.Fprintf(builder, "%s  - %s\n", tag, value)
+	}
+}
+
+func writeRIS(w io.Writer, records []Record) error {
+	var builder strings.Builder
+
+	for i, record := range records {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		writeRISTag(&builder, "TY", "GEN")
+		writeRISTag(&builder, "ID", record.Key)
+		writeRISTag(&builder, "TI", record.Title)
+
+		for _, author := range record.Authors {
+			writeRISTag(&builder, "AU", author)
+		}
+
+		if record.HasYears {
+			writeRISTag(&builder, "PY", fmt.Sprint(record.FromYear))
+
+			if record.ToYear != 0 && record.ToYear != record.FromYear {
+				writeRISTag(&builder, "Y2", fmt.Sprint(record.ToYear))
+			}
+		}
+
+		writeRISTag(&builder, "AB", record.Description)
+		writeRISTag(&builder, "UR", record.URL)
+
+		for _, link := range record.Links {
+			if link != record.URL {
+				writeRISTag(&builder, "UR", link)
+			}
+		}
+
+		for _, file := range record.Files {
+			writeRISTag(&builder, "L1", file.URL)
+		}
+
+		for _, note := range fileNotes(record.Files) {
+			writeRISTag(&builder, "N1", note)
+		}
+
+		writeRISTag(&builder, "DB", ArchiveName)
+		builder.WriteString("ER  - \n")
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/acearchive/artifact-action/bagit"
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportBagitCmd.Flags().String("dir", "", "The `path` of the bag directory")
+	exportBagitCmd.Flags().String("ipfs-api", "", "The `multiaddr` of your IPFS node, to fetch the files from")
+	exportBagitCmd.Flags().Bool("validate", false, "Validate the existing bag in --dir against the current artifacts instead of writing one")
+	exportCmd.AddCommand(exportBagitCmd)
+}
+
+var exportBagitCmd = &cobra.Command{
+	Use:   "bagit",
+	Short: "Export the archive as a BagIt bag",
+	Long: "Export the archive as a BagIt bag.\n\n" +
+		"The bag contains the metadata of the most recent version of each artifact in data/<slug>.json and its " +
+		"files in data/<slug>/, along with SHA-256 and SHA-512 manifests. The content of each file is fetched from " +
+		"your IPFS node. The bag directory must not exist or be empty.\n\n" +
+		"With --validate, the existing bag is checked against its manifests and the current artifacts instead, " +
+		"which doesn't need an IPFS node.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfg.ValidateBagParams(); err != nil {
+			return err
+		}
+
+		artifacts, err := loadCurrentArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if cfg.BagValidation() {
+			return bagit.Validate(cfg.ExportDir(), artifacts)
+		}
+
+		return bagit.Write(cmd.Context(), cfg.ExportDir(), artifacts)
+	},
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/output"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	changelogCmd.Flags().StringP("file", "f", "", "The `path` of the file to write the changelog to instead of stdout")
+	addHistoryTimeoutFlag(changelogCmd)
+	rootCmd.AddCommand(changelogCmd)
+}
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog FROM [TO]",
+	Short: "Summarize how artifacts changed between two commits",
+	Long: "Summarize how artifacts changed between two commits.\n\n" +
+		"FROM and TO may be branches, tags, or commit hashes, and TO defaults to HEAD. The changelog is written as " +
+		"Markdown with sections for new artifacts, new files added, corrections, and removed artifacts, along with " +
+		"the subject of each commit which changed each artifact.",
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := withTimeout(cmd.Context(), cfg.HistoryTimeout())
+		defer cancel()
+
+		from, to := args[0], "HEAD"
+		if len(args) > 1 {
+			to = args[1]
+		}
+
+		changes, err := parse.Changelog(ctx, cfg.Repo(), cfg.Path(), from, to)
+		if err != nil {
+			return err
+		}
+
+		return writeExport(func(w io.Writer) error {
+			return output.WriteChangelog(w, from, to, changes)
+		})
+	},
+}
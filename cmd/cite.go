@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/cite"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportCmd.AddCommand(newCiteCmd("bibtex", "BibTeX", cite.BibTeX))
+	exportCmd.AddCommand(newCiteCmd("csl-json", "CSL-JSON", cite.CSLJSON))
+	exportCmd.AddCommand(newCiteCmd("ris", "RIS", cite.RIS))
+}
+
+// newCiteCmd returns the export subcommand for a citation format.
+func newCiteCmd(use, name string, format cite.Format) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: fmt.Sprintf("Export citations of artifacts as %s", name),
+		Long: fmt.Sprintf("Export citations of artifacts as %s.\n\n", name) +
+			"The most recent version of each artifact is cited, with its slug as the citation key. With --dir, each " +
+			"artifact is written to its own file named after its slug.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifacts, err := loadCurrentArtifacts(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			records := cite.NewRecords(artifacts, cite.Options{SiteURL: cfg.SiteURL(), Gateway: cfg.Gateway()})
+
+			if cfg.ExportDir() == "" {
+				return writeExport(func(w io.Writer) error {
+					return format.Write(w, records)
+				})
+			}
+
+			slugs := make([]string, len(records))
+
+			for i, record := range records {
+				slugs[i] = record.Key
+			}
+
+			return writeArtifactFiles(cfg.ExportDir(), format.Extension, slugs, func(w io.Writer, i int) error {
+				return format.Write(w, []cite.Record{records[i]})
+			})
+		},
+	}
+
+	addArtifactFileFlags(cmd)
+
+	return cmd
+}
+
+// addArtifactFileFlags adds the flags for exports which describe each artifact
+// and can be written to a file per artifact.
+func addArtifactFileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("site-url", "", "The base `url` of artifact pages, which the slug of each artifact is appended to")
+	cmd.Flags().String("gateway", cfg.DefaultGateway, "The base `url` of the IPFS gateway to use in URLs for files")
+	cmd.Flags().String("dir", "", "Write a file for each artifact to the directory at `path` instead of a single file")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/datapackage"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportDataPackageCmd.Flags().String("dir", "", "The `path` of the directory to write the data package to")
+	exportCmd.AddCommand(exportDataPackageCmd)
+}
+
+var exportDataPackageCmd = &cobra.Command{
+	Use:   "datapackage",
+	Short: "Export artifact metadata as a Frictionless Data Package",
+	Long: "Export artifact metadata as a Frictionless Data Package.\n\n" +
+		"The package has a CSV resource for the artifacts, files, people, and links tables, with the most recent " +
+		"version of each artifact, and a datapackage.json which describes them with table schemas. It's written to " +
+		"the directory given by --dir, replacing any existing files with the same names.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfg.ValidateExportDirParams(); err != nil {
+			return err
+		}
+
+		artifacts, err := loadCurrentArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		return datapackage.Write(cfg.ExportDir(), artifacts)
+	},
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/dir"
+	"github.com/acearchive/artifact-action/output"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	dirCmd.Flags().String("ipfs-api", "", "The `multiaddr` of your IPFS node")
+	addHistoryTimeoutFlag(dirCmd)
+	addBuildTimeoutFlag(dirCmd)
+	addOutputFlag(dirCmd)
+	addWhereFlag(dirCmd.Flags())
+	rootCmd.AddCommand(dirCmd)
+}
+
+var dirCmd = &cobra.Command{
+	Use:   "dir",
+	Short: "Build the root directory without pinning anything",
+	Long: "Build the root directory without pinning anything.\n\n" +
+		"This builds a UnixFS directory containing the latest version of each file in each artifact in the " +
+		"history of the repository and adds it to your IPFS node, but does not pin it or its contents to a pinning " +
+		"service. With --where, the root directory only contains the matching artifacts.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if err := cfg.ValidateOutput(); err != nil {
+			return err
+		}
+
+		if err := cfg.ValidateFullHistory(); err != nil {
+			return err
+		}
+
+		if err := cfg.ValidateDirParams(); err != nil {
+			return err
+		}
+
+		var summary runSummary
+
+		artifacts, err := readHistory(ctx)
+		if err != nil {
+			return summary.report(ctx, err)
+		}
+
+		summary.complete("Found %d artifact files in the history", len(artifacts))
+
+		fileCids, err := parse.ExtractCids(artifacts)
+		if err != nil {
+			return err
+		}
+
+		rootCid, err := buildDir(ctx, artifacts)
+		if err != nil {
+			return summary.report(ctx, err)
+		}
+
+		rootCidStr := rootCid.String()
+
+		return output.Print(output.Output{Artifacts: artifacts, RootCid: &rootCidStr}, fileCids)
+	},
+}
+
+// addBuildTimeoutFlag adds the flag for the timeout of building the root
+// directory to a subcommand which does so.
+func addBuildTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().Duration("build-timeout", 0, "The maximum `duration` of building the root directory, or 0 for no timeout")
+}
+
+// buildDir builds the root directory from the most recent version of each
+// artifact in `artifacts` which matches the configured filter.
+func buildDir(ctx context.Context, artifacts []parse.Artifact) (cid.Cid, error) {
+	rootArtifacts, err := filterArtifacts(parse.Latest(artifacts))
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	ctx, cancel := withTimeout(ctx, cfg.BuildTimeout())
+	defer cancel()
+
+	return dir.Build(ctx, rootArtifacts)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/db"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/output"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/cobra"
+)
+
+var ErrInvalidFormat = errors.New("invalid export format")
+
+func init() {
+	exportCmd.PersistentFlags().String("source", string(cfg.DefaultSource), "Where to export artifacts from, either tree or history")
+	exportCmd.PersistentFlags().StringP("file", "f", "", "The `path` of the file to write the export to instead of stdout")
+	exportCmd.PersistentFlags().Duration("history-timeout", 0, "The maximum `duration` of traversing the git history, or 0 for no timeout")
+	addWhereFlag(exportCmd.PersistentFlags())
+	addLatestOnlyFlag(exportCmd.PersistentFlags())
+	exportCmd.Flags().String("format", "", "The format to export, equivalent to the subcommand of the same name")
+
+	if err := exportCmd.RegisterFlagCompletionFunc("source", completeValues(cfg.Sources())); err != nil {
+		panic(err)
+	}
+
+	if err := exportCmd.RegisterFlagCompletionFunc("format", completeExportFormats); err != nil {
+		panic(err)
+	}
+
+	addSortFlag(exportJSONCmd)
+	exportCmd.AddCommand(exportJSONCmd)
+	exportCmd.AddCommand(exportSqliteCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export artifact metadata in another format",
+	Long: "Export artifact metadata in another format.\n\n" +
+		"Each format is a subcommand of this command. The format can also be selected with the --format flag.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cfg.ExportFormat() == "" {
+			return cmd.Help()
+		}
+
+		return dispatch(cmd, cfg.ExportFormat(), ErrInvalidFormat)
+	},
+}
+
+var exportJSONCmd = &cobra.Command{
+	Use:   "json",
+	Short: "Export artifact metadata as the JSON document from the artifacts output",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifacts, err := loadExportArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		return writeExport(func(w io.Writer) error {
+			return output.WriteArtifacts(w, output.Output{Artifacts: artifacts, RootCid: nil})
+		})
+	},
+}
+
+var exportSqliteCmd = &cobra.Command{
+	Use:   "sqlite",
+	Short: "Export every version of every artifact as a SQLite database",
+	Long: "Export every version of every artifact as a SQLite database.\n\n" +
+		"The database can't be written to stdout, so --file is required. If the file already exists, it's replaced.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfg.ValidateExportFileParams(); err != nil {
+			return err
+		}
+
+		artifacts, err := loadExportArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		return db.Export(cmd.Context(), cfg.ExportFile(), artifacts)
+	},
+}
+
+func completeExportFormats(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	formats := make([]string, 0, len(exportCmd.Commands()))
+
+	for _, formatCmd := range exportCmd.Commands() {
+		formats = append(formats, formatCmd.Name())
+	}
+
+	return formats, cobra.ShellCompDirectiveNoFileComp
+}
+
+// loadExportArtifacts returns the artifacts from the configured source.
+func loadExportArtifacts(ctx context.Context) ([]parse.Artifact, error) {
+	if err := cfg.ValidateSource(); err != nil {
+		return nil, err
+	}
+
+	if cfg.ExportSource() == cfg.SourceTree {
+		artifacts, err := parse.Tree(ctx, cfg.Repo(), cfg.Path())
+		if err != nil {
+			return nil, err
+		}
+
+		return filterArtifacts(artifacts)
+	}
+
+	return loadHistory(ctx)
+}
+
+// loadCurrentArtifacts returns the artifacts from the configured source for
+// exports which describe the archive as it is now, using only the most recent
+// version of each artifact. With the history as the source, artifacts which
+// were renamed or deleted are left out, since they're no longer part of the
+// archive.
+func loadCurrentArtifacts(ctx context.Context) ([]parse.Artifact, error) {
+	artifacts, err := loadExportArtifacts(ctx)
+	if err != nil || cfg.ExportSource() == cfg.SourceTree {
+		return artifacts, err
+	}
+
+	current, err := parse.HeadSlugs(cfg.Repo(), cfg.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	return parse.WithSlugs(artifacts, current), nil
+}
+
+// writeArtifactFiles calls `write` with the index of each artifact in `slugs`
+// and a file in `dir` named after its slug with the given extension.
+func writeArtifactFiles(dir, extension string, slugs []string, write func(w io.Writer, index int) error) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for i, slug := range slugs {
+		if err := writeFile(filepath.Join(dir, fmt.Sprintf("%s.%s", slug, extension)), func(w io.Writer) error {
+			return write(w, i)
+		}); err != nil {
+			return err
+		}
+	}
+
+	logger.LogInfo(fmt.Sprintf("Wrote %d files to %s", len(slugs), dir), logger.Phase("export"))
+
+	return nil
+}
+
+// writeFile calls `write` with the file at `path`, replacing it if it already
+// exists.
+func writeFile(path string, write func(w io.Writer) error) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	return write(file)
+}
+
+// writeExport calls `write` with the configured export file, or stdout if
+// there isn't one.
+func writeExport(write func(w io.Writer) error) error {
+	if cfg.ExportFile() == "" {
+		return write(os.Stdout)
+	}
+
+	return writeFile(cfg.ExportFile(), write)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/feed"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addFeedFlags(exportAtomCmd)
+	addFeedFlags(exportRSSCmd)
+	exportCmd.AddCommand(exportAtomCmd)
+	exportCmd.AddCommand(exportRSSCmd)
+}
+
+// addFeedFlags adds the flags for configuring a feed to a subcommand which
+// exports one.
+func addFeedFlags(cmd *cobra.Command) {
+	cmd.Flags().String("site-url", "", "The base `url` of artifact pages, which the slug of each artifact is appended to")
+	cmd.Flags().String("feed-title", cfg.DefaultFeedTitle, "The `title` of the feed")
+	cmd.Flags().Bool("updates", false, "Include an entry each time files are added to an artifact")
+	cmd.Flags().Int("limit", 0, "The maximum `number` of entries, or 0 for no limit")
+}
+
+func feedOptions() feed.Options {
+	return feed.Options{
+		SiteURL: cfg.SiteURL(),
+		Title:   cfg.FeedTitle(),
+		Updates: cfg.FeedUpdates(),
+		Limit:   cfg.FeedLimit(),
+		Current: nil,
+		Renames: nil,
+	}
+}
+
+// exportFeed exports a feed of the history with `write`.
+func exportFeed(cmd *cobra.Command, write func(io.Writer, []parse.Artifact, feed.Options) error) error {
+	if err := cfg.ValidateSiteParams(); err != nil {
+		return err
+	}
+
+	// Feeds need to know when each artifact was added, so they always come
+	// from the history.
+	artifacts, err := loadHistory(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	opts := feedOptions()
+
+	if opts.Current, err = parse.HeadSlugs(cfg.Repo(), cfg.Path()); err != nil {
+		return err
+	}
+
+	if opts.Renames, err = loadRenames(cmd.Context()); err != nil {
+		return err
+	}
+
+	return writeExport(func(w io.Writer) error {
+		return write(w, artifacts, opts)
+	})
+}
+
+var exportAtomCmd = &cobra.Command{
+	Use:   "atom",
+	Short: "Export an Atom feed of new artifacts",
+	Long: "Export an Atom feed of new artifacts.\n\n" +
+		"Each artifact gets an entry for the commit which added it, and with --updates, for each commit which added " +
+		"files to it. The feed is always built from the history, renamed artifacts keep their entry, and deleted " +
+		"artifacts are left out.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportFeed(cmd, feed.WriteAtom)
+	},
+}
+
+var exportRSSCmd = &cobra.Command{
+	Use:   "rss",
+	Short: "Export an RSS 2.0 feed of new artifacts",
+	Long: "Export an RSS 2.0 feed of new artifacts.\n\n" +
+		"Each artifact gets an item for the commit which added it, and with --updates, for each commit which added " +
+		"files to it. The feed is always built from the history, renamed artifacts keep their item, and deleted " +
+		"artifacts are left out.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportFeed(cmd, feed.WriteRSS)
+	},
+}
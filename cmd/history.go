@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/output"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	addHistoryTimeoutFlag(historyCmd)
+	addOutputFlag(historyCmd)
+	addWhereFlag(historyCmd.Flags())
+	addLatestOnlyFlag(historyCmd.Flags())
+	rootCmd.AddCommand(historyCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Query every version of every artifact in the repository",
+	Long: "Query every version of every artifact in the repository.\n\n" +
+		"The entire commit history is traversed to pull each version of each artifact file. Artifact files which " +
+		"are not valid YAML are skipped silently.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if err := cfg.ValidateOutput(); err != nil {
+			return err
+		}
+
+		var summary runSummary
+
+		if cfg.Streaming() {
+			return summary.report(ctx, output.Stream(func(printArtifact func(parse.Artifact) error) error {
+				printArtifact, err := filterWalk(printArtifact)
+				if err != nil {
+					return err
+				}
+
+				ctx, cancel := withTimeout(ctx, cfg.HistoryTimeout())
+				defer cancel()
+
+				return parse.WalkHistory(ctx, cfg.Repo(), cfg.Path(), printArtifact)
+			}))
+		}
+
+		artifacts, err := loadHistory(ctx)
+		if err != nil {
+			return summary.report(ctx, err)
+		}
+
+		fileCids, err := parse.ExtractCids(artifacts)
+		if err != nil {
+			return err
+		}
+
+		return output.Print(output.Output{Artifacts: artifacts, RootCid: nil}, fileCids)
+	},
+}
+
+// addHistoryTimeoutFlag adds the flag for the timeout of traversing the git
+// history to a subcommand which does so.
+func addHistoryTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().Duration("history-timeout", 0, "The maximum `duration` of traversing the git history, or 0 for no timeout")
+}
+
+// addLatestOnlyFlag adds the flag for only including the most recent version
+// of each artifact to the flags of a subcommand which reads the history.
+func addLatestOnlyFlag(flags *pflag.FlagSet) {
+	flags.Bool("latest-only", false, "Only include the most recent version of each artifact")
+}
+
+// readHistory returns every version of every artifact in the history of the
+// repository, ignoring any configured filter.
+func readHistory(ctx context.Context) ([]parse.Artifact, error) {
+	ctx, cancel := withTimeout(ctx, cfg.HistoryTimeout())
+	defer cancel()
+
+	return parse.History(ctx, cfg.Repo(), cfg.Path())
+}
+
+// loadHistory returns every version of every artifact in the history of the
+// repository which matches the configured filter. With --latest-only, the
+// filter is applied to the most recent version of each artifact.
+func loadHistory(ctx context.Context) ([]parse.Artifact, error) {
+	artifacts, err := readHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.LatestOnly() {
+		artifacts = parse.Latest(artifacts)
+	}
+
+	return filterArtifacts(artifacts)
+}
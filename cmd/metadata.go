@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/metadata"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportCmd.AddCommand(newMetadataCmd("jsonld", "JSON-LD", "schema.org", "json", metadata.WriteJSONLD))
+	exportCmd.AddCommand(newMetadataCmd("dublin-core", "Dublin Core XML", "Dublin Core", "xml", metadata.WriteDublinCore))
+}
+
+// newMetadataCmd returns the export subcommand for a metadata format.
+func newMetadataCmd(
+	use, name, vocabulary, extension string,
+	write func(io.Writer, []metadata.Record, metadata.Options) error,
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: fmt.Sprintf("Export %s metadata of artifacts as %s", vocabulary, name),
+		Long: fmt.Sprintf("Export %s metadata of artifacts as %s.\n\n", vocabulary, name) +
+			fmt.Sprintf("The most recent version of each artifact is exported. Artifacts which aren't version %d of ", metadata.SchemaVersion) +
+			"the schema are skipped. With --dir, each artifact is written to its own file named after its slug.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifacts, err := loadCurrentArtifacts(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			opts := metadata.Options{SiteURL: cfg.SiteURL(), Gateway: cfg.Gateway()}
+
+			records, err := metadata.NewRecords(artifacts, opts)
+			if err != nil {
+				return err
+			}
+
+			if cfg.ExportDir() == "" {
+				return writeExport(func(w io.Writer) error {
+					return write(w, records, opts)
+				})
+			}
+
+			slugs := make([]string, len(records))
+
+			for i, record := range records {
+				slugs[i] = record.Slug
+			}
+
+			return writeArtifactFiles(cfg.ExportDir(), extension, slugs, func(w io.Writer, i int) error {
+				return write(w, []metadata.Record{records[i]}, opts)
+			})
+		},
+	}
+
+	addArtifactFileFlags(cmd)
+
+	return cmd
+}
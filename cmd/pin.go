@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/output"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/pin"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	pinCmd.Flags().String("ipfs-api", "", "The `multiaddr` of your IPFS node")
+	pinCmd.Flags().String("pin-endpoint", "", "The `url` of the IPFS pinning service API endpoint to use")
+	pinCmd.Flags().String("pin-token", "", "The secret bearer `token` for the configured IPFS pinning service")
+	pinCmd.Flags().Bool("dry-run", false, "Prevents actually pinning any files")
+	pinCmd.Flags().Duration("pin-timeout", 0, "The maximum `duration` of pinning files, or 0 for no timeout")
+	addHistoryTimeoutFlag(pinCmd)
+	addBuildTimeoutFlag(pinCmd)
+	addOutputFlag(pinCmd)
+	addWhereFlag(pinCmd.Flags())
+	rootCmd.AddCommand(pinCmd)
+}
+
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Pin every file in the history of the repository",
+	Long: "Pin every file in the history of the repository.\n\n" +
+		"The entire commit history is traversed to pull each version of each artifact file, and the files in them " +
+		"are pinned to an IPFS pinning service along with a root directory containing the latest version of each " +
+		"file. Files which are already pinned are skipped. With --where, the root directory only contains the " +
+		"matching artifacts, but every file is still pinned.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if err := cfg.ValidateOutput(); err != nil {
+			return err
+		}
+
+		if err := cfg.ValidateFullHistory(); err != nil {
+			return err
+		}
+
+		if err := cfg.ValidatePinParams(); err != nil {
+			return err
+		}
+
+		if cfg.DryRun() {
+			logger.LogNotice("This is a dry run. No files will actually be uploaded.")
+		}
+
+		var summary runSummary
+
+		artifacts, err := readHistory(ctx)
+		if err != nil {
+			return summary.report(ctx, err)
+		}
+
+		summary.complete("Found %d artifact files in the history", len(artifacts))
+
+		fileCids, err := parse.ExtractCids(artifacts)
+		if err != nil {
+			return err
+		}
+
+		rootCid, err := buildDir(ctx, artifacts)
+		if err != nil {
+			return summary.report(ctx, err)
+		}
+
+		summary.complete("Built the root directory: /ipfs/%s", rootCid.String())
+
+		pinCtx, cancelPin := withTimeout(ctx, cfg.PinTimeout())
+		defer cancelPin()
+
+		pinStats, err := pin.Pin(pinCtx, cfg.PinEndpoint(), cfg.PinToken(), fileCids, rootCid)
+		if err != nil {
+			if pinStats.ToPin > 0 {
+				summary.complete("Skipped %d files that were already pinned", pinStats.AlreadyPinned)
+				summary.complete("Pinned %d of %d files", len(pinStats.Pinned), pinStats.ToPin)
+			}
+
+			return summary.report(ctx, err)
+		}
+
+		rootCidStr := rootCid.String()
+
+		actionOutput := output.Output{
+			Artifacts: artifacts,
+			RootCid:   &rootCidStr,
+			Invalid:   nil,
+			PinStats:  &pinStats,
+		}
+
+		if err := output.Print(actionOutput, fileCids); err != nil {
+			return err
+		}
+
+		if cfg.DryRun() {
+			logger.LogNotice("This was a dry run. No files were actually uploaded.")
+		}
+
+		return nil
+	},
+}
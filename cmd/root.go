@@ -4,35 +4,59 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/acearchive/artifact-action/cfg"
-	"github.com/acearchive/artifact-action/dir"
 	"github.com/acearchive/artifact-action/logger"
-	"github.com/acearchive/artifact-action/output"
-	"github.com/acearchive/artifact-action/parse"
-	"github.com/acearchive/artifact-action/pin"
+	"github.com/acearchive/artifact-action/table"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var ErrInvalidMode = errors.New("invalid mode parameter")
 
+// cancelTimeout releases the timer for the global timeout, if there is one.
+var cancelTimeout context.CancelFunc = func() {}
+
 func init() {
-	rootCmd.Flags().StringP("repo", "r", ".", "The `path` of the git repo containing the artifact files")
-	rootCmd.Flags().StringP("mode", "m", string(cfg.DefaultMode), "The mode to operate in")
-	rootCmd.Flags().String("path", cfg.DefaultPath, "The `path` of the artifact files in the repository")
+	rootCmd.PersistentFlags().StringP("repo", "r", ".", "The `path` of the git repo containing the artifact files")
+	rootCmd.PersistentFlags().String("path", cfg.DefaultPath, "The `path` of the artifact files in the repository")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "The maximum `duration` of the whole run, or 0 for no timeout")
+	rootCmd.PersistentFlags().String("log-format", string(cfg.DefaultLogFormat), "The format of log messages on stderr, either text or json")
+	rootCmd.PersistentFlags().String("log-level", string(cfg.DefaultLogLevel), "The minimum level of log messages to print")
+	rootCmd.PersistentFlags().Bool("action", false, "Run this tool as a GitHub Action")
+	rootCmd.Flags().StringP("mode", "m", string(cfg.DefaultMode), "The subcommand to run; kept for compatibility with the GitHub Action")
+
+	// These flags belonged to the root command before modes became
+	// subcommands. They're kept so that existing `--mode` invocations keep
+	// working, and are handed to whichever subcommand `--mode` selects.
+	rootCmd.Flags().StringP("output", "o", "", "Print the given output type to stdout instead of summary statistics")
 	rootCmd.Flags().String("ipfs-api", "", "The `multiaddr` of your IPFS node")
 	rootCmd.Flags().String("pin-endpoint", "", "The `url` of the IPFS pinning service API endpoint to use")
 	rootCmd.Flags().String("pin-token", "", "The secret bearer `token` for the configured IPFS pinning service")
-	rootCmd.Flags().StringP("output", "o", "", "Print the given output type to stdout instead of summary statistics")
-	rootCmd.Flags().Bool("dry-run", false, "Prevents uploading files when used in upload mode")
-	rootCmd.Flags().Bool("action", false, "Run this tool as a GitHub Action")
+	rootCmd.Flags().Bool("dry-run", false, "Prevents actually pinning any files")
+
+	if err := rootCmd.PersistentFlags().MarkHidden("action"); err != nil {
+		panic(err)
+	}
+
+	for _, name := range []string{"mode", "output", "ipfs-api", "pin-endpoint", "pin-token", "dry-run"} {
+		if err := rootCmd.Flags().MarkHidden(name); err != nil {
+			panic(err)
+		}
+	}
+
+	logFormats := []string{string(cfg.LogFormatText), string(cfg.LogFormatJSON)}
 
-	if err := rootCmd.Flags().MarkHidden("action"); err != nil {
+	if err := rootCmd.RegisterFlagCompletionFunc("log-format", completeValues(logFormats)); err != nil {
 		panic(err)
 	}
 
-	if err := viper.BindPFlags(rootCmd.Flags()); err != nil {
+	if err := rootCmd.RegisterFlagCompletionFunc("log-level", completeValues(cfg.LogLevels())); err != nil {
 		panic(err)
 	}
 }
@@ -42,79 +66,167 @@ var rootCmd = &cobra.Command{
 	Long:  "Host content from Ace Archive on the IPFS network.\n\nSee the README for details.",
 	Short: "Host content from Ace Archive on the IPFS network",
 	Args:  cobra.NoArgs,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx := context.Background()
-
-		if err := cfg.ValidateParams(); err != nil {
+	// Errors are logged by `Execute`.
+	SilenceErrors: true,
+	// Only the flags of the command actually being run are bound, because
+	// subcommands are free to define flags with the same name.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
 			return err
 		}
 
-		if cfg.DryRun() {
-			if cfg.Mode() == cfg.ModePin {
-				logger.LogNotice("This is a dry run. No files will actually be uploaded.")
-			} else {
-				logger.LogWarning(fmt.Sprintf("Using the %s option is pointless when not in `upload` mode.", cfg.StringifyInput("dry-run")))
-			}
+		if err := cfg.ValidateLogging(); err != nil {
+			return err
 		}
 
-		var (
-			artifacts []parse.Artifact
-			err       error
-		)
-
-		switch mode := cfg.Mode(); mode {
-		case cfg.ModeValidate:
-			artifacts, err = parse.Tree(cfg.Repo(), cfg.Path())
-			if err != nil {
-				return err
-			}
-		case cfg.ModeHistory, cfg.ModePin:
-			artifacts, err = parse.History(cfg.Repo(), cfg.Path())
-			if err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("%w: %s", ErrInvalidMode, mode)
+		if err := cfg.ValidateSort(); err != nil {
+			return err
 		}
 
-		fileCids, err := parse.ExtractCids(artifacts)
-		if err != nil {
+		// Check the filter up front rather than after traversing the history.
+		if _, err := whereFilter(); err != nil {
 			return err
 		}
 
-		actionOutput := output.Output{
-			Artifacts: artifacts,
-			RootCid:   nil,
-		}
+		// The flags have been parsed successfully at this point, so any
+		// further errors aren't usage errors.
+		cmd.SilenceUsage = true
 
-		if cfg.Mode() == cfg.ModePin {
-			rootCid, err := dir.Build(ctx, artifacts)
-			if err != nil {
-				return err
-			}
+		if cfg.Timeout() > 0 {
+			var ctx context.Context
 
-			rootCidStr := rootCid.String()
-			actionOutput.RootCid = &rootCidStr
+			ctx, cancelTimeout = context.WithTimeout(cmd.Context(), cfg.Timeout())
+			cmd.SetContext(ctx)
+		}
 
-			if err := pin.Pin(ctx, cfg.PinEndpoint(), cfg.PinToken(), fileCids, rootCid); err != nil {
-				return err
-			}
+		return nil
+	},
+	// Running the root command directly dispatches to the subcommand named by
+	// `--mode`. This is how the GitHub Action, which can only pass its inputs
+	// through the environment, selects what to do.
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode := cfg.Mode()
+
+		if cfg.DryRun() && mode != cfg.ModePin {
+			logger.LogWarning(fmt.Sprintf("Using the %s option is pointless when not in `pin` mode.", cfg.StringifyInput("dry-run")))
 		}
 
-		if err := output.Print(actionOutput, fileCids); err != nil {
-			return err
+		return dispatch(cmd, string(mode), ErrInvalidMode)
+	},
+}
+
+// dispatch runs the subcommand of `parent` with the given name as if it had
+// been invoked directly, returning `errInvalid` if there is no such
+// subcommand.
+func dispatch(parent *cobra.Command, name string, errInvalid error) error {
+	subCmd, _, err := parent.Find([]string{name})
+	if err != nil || subCmd == parent || subCmd.RunE == nil {
+		return fmt.Errorf("%w: %s", errInvalid, name)
+	}
+
+	// The subcommand's flags were never parsed, but we still need them bound
+	// so that their defaults apply. Flags which were passed to the parent
+	// under the same name are already bound and take precedence.
+	var bindErr error
+
+	subCmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if parentFlag := parent.Flags().Lookup(flag.Name); parentFlag != nil && parentFlag.Changed {
+			return
 		}
 
-		if cfg.DryRun() && cfg.Mode() == cfg.ModePin {
-			logger.LogNotice("This was a dry run. No files were actually uploaded.")
+		if err := viper.BindPFlag(flag.Name, flag); err != nil && bindErr == nil {
+			bindErr = err
 		}
+	})
 
-		return nil
-	},
+	if bindErr != nil {
+		return bindErr
+	}
+
+	subCmd.SetContext(parent.Context())
+
+	return subCmd.RunE(subCmd, nil)
+}
+
+// addOutputFlag adds the flag for selecting which output to print to stdout
+// to a subcommand that supports it.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "", "Print the given output type to stdout instead of summary statistics")
+	cmd.Flags().String("output-file", "", "Write the selected output to the file at `path` instead of stdout")
+	cmd.Flags().String("table", string(table.Artifacts), "The table to print with csv and tsv output, either artifacts, files, people, or links")
+	cmd.Flags().StringSlice("columns", nil, "The comma-separated `columns` to include with csv and tsv output")
+	cmd.Flags().String("template", "", "Render the output with the text/template in the file at `path`, implies --output template")
+	cmd.Flags().String("gateway", cfg.DefaultGateway, "The base `url` of the IPFS gateway to use in URLs for files")
+	addSortFlag(cmd)
+
+	if err := cmd.RegisterFlagCompletionFunc("output", completeValues(cfg.OutputTypes())); err != nil {
+		panic(err)
+	}
+
+	if err := cmd.RegisterFlagCompletionFunc("table", completeValues(table.Names())); err != nil {
+		panic(err)
+	}
+
+	if err := cmd.RegisterFlagCompletionFunc("columns", completeColumns); err != nil {
+		panic(err)
+	}
+}
+
+// addSortFlag adds the flag for the order to print artifacts in to a
+// subcommand that prints them.
+func addSortFlag(cmd *cobra.Command) {
+	cmd.Flags().String("sort", string(cfg.DefaultSort), "The order to print artifacts in, either slug, date, newest, title, or none")
+
+	if err := cmd.RegisterFlagCompletionFunc("sort", completeValues(cfg.SortOrders())); err != nil {
+		panic(err)
+	}
+}
+
+func completeColumns(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	tableName, err := cmd.Flags().GetString("table")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return table.Columns(table.Name(tableName)), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeValues returns a completion function which completes a flag with a
+// fixed set of values.
+func completeValues(values []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// withTimeout returns a context which is cancelled after `timeout`, or when
+// `ctx` is cancelled if `timeout` is zero.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// When a GitHub job is cancelled, the runner sends SIGINT and then SIGTERM
+	// before killing the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	// Once the first signal has cancelled the run, go back to the default
+	// behavior so that a second signal kills the process immediately.
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+
+	cancelTimeout()
+	stop()
+
+	if err != nil {
 		logger.LogError(err)
 		logger.Exit()
 	}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/acearchive/artifact-action/search"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportCmd.AddCommand(exportSearchIndexCmd)
+}
+
+var exportSearchIndexCmd = &cobra.Command{
+	Use:   "search-index",
+	Short: "Export a prebuilt search index of the artifacts",
+	Long: "Export a prebuilt search index of the artifacts.\n\n" +
+		"The index is a serialized MiniSearch index of the title, description, people, identities, and aliases of " +
+		"the most recent version of each artifact, with Markdown removed from the title and description. The ID of " +
+		"each document is the slug of the artifact.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		artifacts, err := loadCurrentArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		return writeExport(func(w io.Writer) error {
+			return search.WriteIndex(w, artifacts)
+		})
+	},
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/metadata"
+	"github.com/acearchive/artifact-action/oai"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout is how long to wait for in-flight requests to finish once
+// the server is stopped.
+const shutdownTimeout = 10 * time.Second
+
+func init() {
+	serveOAIPMHCmd.Flags().String("addr", cfg.DefaultServeAddr, "The TCP `address` to listen on")
+	serveOAIPMHCmd.Flags().String("repository-name", cfg.DefaultRepositoryName, "The human-readable `name` of the repository")
+	serveOAIPMHCmd.Flags().String("oai-identifier", cfg.DefaultOAIIdentifier, "The `namespace` of OAI identifiers, usually the domain name of the site")
+	serveOAIPMHCmd.Flags().String("admin-email", "", "The email `address` of the administrator of the repository")
+	serveOAIPMHCmd.Flags().Int("page-size", oai.DefaultPageSize, "The maximum `number` of records in a single response")
+	serveOAIPMHCmd.Flags().String("site-url", "", "The base `url` of artifact pages, which the slug of each artifact is appended to")
+	serveOAIPMHCmd.Flags().String("gateway", cfg.DefaultGateway, "The base `url` of the IPFS gateway to use in URLs for files")
+	addHistoryTimeoutFlag(serveOAIPMHCmd)
+	addWhereFlag(serveOAIPMHCmd.Flags())
+
+	serveCmd.AddCommand(serveOAIPMHCmd)
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve artifact metadata over HTTP",
+	Args:  cobra.NoArgs,
+}
+
+// listenAndServe serves `handler` on the configured address until `ctx` is
+// cancelled, at which point it shuts down gracefully.
+func listenAndServe(ctx context.Context, handler http.Handler) error {
+	server := &http.Server{
+		Addr:              cfg.ServeAddr(),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	logger.LogInfo(fmt.Sprintf("Listening on %s", cfg.ServeAddr()), logger.Phase("serve"))
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.LogInfo("Shutting down the server", logger.Phase("serve"))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+var serveOAIPMHCmd = &cobra.Command{
+	Use:   "oai-pmh",
+	Short: "Serve an OAI-PMH repository of the artifacts",
+	Long: "Serve an OAI-PMH repository of the artifacts.\n\n" +
+		"Each artifact is a record with simple Dublin Core (oai_dc) metadata of its most recent version, and its " +
+		"datestamp is the date of the commit which last modified it. There's a set for each identity and each " +
+		"decade. Only artifacts which are still in the tree at HEAD are included. The history is read once at startup.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if err := cfg.ValidateOAIParams(); err != nil {
+			return err
+		}
+
+		artifacts, err := loadHistory(ctx)
+		if err != nil {
+			return err
+		}
+
+		// The repository doesn't keep track of deleted records, so artifacts
+		// which were renamed or deleted aren't in it.
+		current, err := parse.HeadSlugs(cfg.Repo(), cfg.Path())
+		if err != nil {
+			return err
+		}
+
+		handler := oai.NewHandler(parse.WithSlugs(artifacts, current), oai.Options{
+			Name:       cfg.RepositoryName(),
+			Identifier: cfg.OAIIdentifier(),
+			AdminEmail: cfg.AdminEmail(),
+			PageSize:   cfg.PageSize(),
+			Metadata:   metadata.Options{SiteURL: cfg.SiteURL(), Gateway: cfg.Gateway()},
+		})
+
+		return listenAndServe(ctx, handler)
+	},
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/site"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	exportSitemapCmd.Flags().String("site-url", "", "The base `url` of artifact pages, which the slug of each artifact is appended to")
+	exportRedirectsCmd.Flags().String("site-url", "", "The base `url` of artifact pages, which the slug of each artifact is appended to")
+	exportRedirectsCmd.Flags().String("redirect-format", string(cfg.RedirectFormatNetlify), "The format of the redirect rules, either netlify, cloudflare, or nginx")
+
+	if err := exportRedirectsCmd.RegisterFlagCompletionFunc("redirect-format", completeValues(cfg.RedirectFormats())); err != nil {
+		panic(err)
+	}
+
+	exportCmd.AddCommand(exportSitemapCmd)
+	exportCmd.AddCommand(exportRedirectsCmd)
+}
+
+// loadSiteArtifacts returns the artifacts in the tree, which are the ones on
+// the site, along with every version of every artifact in the history.
+func loadSiteArtifacts(ctx context.Context) (current, history []parse.Artifact, err error) {
+	if current, err = parse.Tree(ctx, cfg.Repo(), cfg.Path()); err != nil {
+		return nil, nil, err
+	}
+
+	if current, err = filterArtifacts(current); err != nil {
+		return nil, nil, err
+	}
+
+	if history, err = loadHistory(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return current, history, nil
+}
+
+// loadRenames returns the renames of artifact files in the history of the
+// repository.
+func loadRenames(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := withTimeout(ctx, cfg.HistoryTimeout())
+	defer cancel()
+
+	return parse.Renames(ctx, cfg.Repo(), cfg.Path())
+}
+
+var exportSitemapCmd = &cobra.Command{
+	Use:   "sitemap",
+	Short: "Export a sitemap of the page for each artifact",
+	Long: "Export a sitemap of the page for each artifact.\n\n" +
+		"There's a URL for each artifact in the tree, and its last modification date is the date of the commit which " +
+		"last modified it.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfg.ValidateSiteParams(); err != nil {
+			return err
+		}
+
+		current, history, err := loadSiteArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		return writeExport(func(w io.Writer) error {
+			return site.WriteSitemap(w, current, history, cfg.SiteURL())
+		})
+	},
+}
+
+var exportRedirectsCmd = &cobra.Command{
+	Use:   "redirects",
+	Short: "Export redirect rules for aliases and old slugs of artifacts",
+	Long: "Export redirect rules for aliases and old slugs of artifacts.\n\n" +
+		"Every alias in any version of an artifact redirects to it. Every slug which is in the history but no longer " +
+		"in the tree redirects to the current artifact its file was renamed to, or else to the only current artifact " +
+		"which shares a file with it, or to the list of artifacts if there isn't one, so that old URLs never 404. The rules can be written for Netlify, Cloudflare Pages, or " +
+		"nginx.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfg.ValidateSiteParams(); err != nil {
+			return err
+		}
+
+		if err := cfg.ValidateRedirectFormat(); err != nil {
+			return err
+		}
+
+		current, history, err := loadSiteArtifacts(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		renames, err := loadRenames(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		redirects := site.Redirects(current, history, renames)
+
+		write := site.WriteNetlify
+
+		switch cfg.RedirectFormat() {
+		case cfg.RedirectFormatNetlify:
+		case cfg.RedirectFormatCloudflare:
+			write = site.WriteCloudflare
+		case cfg.RedirectFormatNginx:
+			write = site.WriteNginx
+		}
+
+		return writeExport(func(w io.Writer) error {
+			return write(w, redirects, cfg.SiteURL())
+		})
+	},
+}
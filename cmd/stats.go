@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/stats"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	statsCmd.Flags().String("format", string(cfg.StatsFormatMarkdown), "The format of the report, either markdown or json")
+	statsCmd.Flags().StringP("file", "f", "", "The `path` of the file to write the report to instead of stdout")
+	statsCmd.Flags().String("ipfs-api", "", "The `multiaddr` of your IPFS node, to report the total size of the files")
+	addHistoryTimeoutFlag(statsCmd)
+	addWhereFlag(statsCmd.Flags())
+
+	formats := []string{string(cfg.StatsFormatMarkdown), string(cfg.StatsFormatJSON)}
+
+	if err := statsCmd.RegisterFlagCompletionFunc("format", completeValues(formats)); err != nil {
+		panic(err)
+	}
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report statistics about the artifacts in the repository",
+	Long: "Report statistics about the artifacts in the repository.\n\n" +
+		"The entire commit history is traversed to count versions and unique CIDs. Other statistics describe " +
+		"the most recent version of each artifact which is still in the tree at HEAD, so artifacts which were " +
+		"renamed or deleted aren't counted. If an IPFS node is configured, the total size of the " +
+		"files is reported too.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if err := cfg.ValidateStatsFormat(); err != nil {
+			return err
+		}
+
+		var summary runSummary
+
+		artifacts, err := loadHistory(ctx)
+		if err != nil {
+			return summary.report(ctx, err)
+		}
+
+		fileCids, err := parse.ExtractCids(artifacts)
+		if err != nil {
+			return err
+		}
+
+		current, err := parse.HeadSlugs(cfg.Repo(), cfg.Path())
+		if err != nil {
+			return err
+		}
+
+		report := stats.Compute(artifacts, current, fileCids)
+
+		if cfg.IpfsAPI() != "" {
+			totalSize, err := stats.TotalSize(ctx, fileCids)
+			if err != nil {
+				return err
+			}
+
+			report.TotalSize = &totalSize
+		}
+
+		return writeExport(func(w io.Writer) error {
+			if cfg.StatsFormat() == cfg.StatsFormatMarkdown {
+				_, err := fmt.Fprint(w, report.Markdown())
+				return err
+			}
+
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(report)
+		})
+	},
+}
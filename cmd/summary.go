@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/acearchive/artifact-action/logger"
+)
+
+// runSummary records the steps a run has completed so that they can be
+// reported if the run is cancelled or times out part way through.
+type runSummary struct {
+	completed []string
+}
+
+func (s *runSummary) complete(format string, a ...interface{}) {
+	s.completed = append(s.completed, fmt.Sprintf(format, a...))
+}
+
+// report logs what the run completed if `err` was caused by the run being
+// cancelled or timing out. It always returns `err` unchanged.
+func (s *runSummary) report(ctx context.Context, err error) error {
+	var reason string
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		reason = "The run timed out before it could finish."
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		reason = "The run was cancelled before it could finish."
+	default:
+		return err
+	}
+
+	logger.LogWarning(reason)
+
+	if len(s.completed) > 0 {
+		logger.LogGroup("Completed before stopping:", s.completed)
+	}
+
+	return err
+}
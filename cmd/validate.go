@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/output"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFlag(validateCmd)
+	addWhereFlag(validateCmd.Flags())
+	rootCmd.AddCommand(validateCmd)
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the artifact files in the working tree",
+	Long: "Validate the artifact files in the working tree.\n\n" +
+		"Artifact files are pulled from the working tree and their syntax is validated. If any artifact file has " +
+		"invalid syntax, this command fails after printing the output for the valid ones.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cfg.ValidateOutput(); err != nil {
+			return err
+		}
+
+		if cfg.Streaming() {
+			return output.Stream(func(printArtifact func(parse.Artifact) error) error {
+				printArtifact, err := filterWalk(printArtifact)
+				if err != nil {
+					return err
+				}
+
+				return parse.WalkTree(cmd.Context(), cfg.Repo(), cfg.Path(), printArtifact)
+			})
+		}
+
+		var invalidErr parse.InvalidArtifactFilesError
+
+		artifacts, treeErr := parse.Tree(cmd.Context(), cfg.Repo(), cfg.Path())
+		if treeErr != nil && !errors.As(treeErr, &invalidErr) {
+			return treeErr
+		}
+
+		artifacts, err := filterArtifacts(artifacts)
+		if err != nil {
+			return err
+		}
+
+		fileCids, err := parse.ExtractCids(artifacts)
+		if err != nil {
+			return err
+		}
+
+		actionOutput := output.Output{
+			Artifacts: artifacts,
+			RootCid:   nil,
+			Invalid:   invalidErr.Errors,
+			PinStats:  nil,
+		}
+
+		if err := output.Print(actionOutput, fileCids); err != nil {
+			return err
+		}
+
+		return treeErr
+	},
+}
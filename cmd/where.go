@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/filter"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/spf13/pflag"
+)
+
+// addWhereFlag adds the flag for filtering artifacts to the flags of a
+// subcommand that supports it.
+func addWhereFlag(flags *pflag.FlagSet) {
+	flags.String("where", "", "Only include artifacts which match the filter `expression`")
+}
+
+// whereFilter returns the configured filter, or nil if there isn't one.
+func whereFilter() (*filter.Filter, error) {
+	if cfg.Where() == "" {
+		return nil, nil
+	}
+
+	return filter.Parse(cfg.Where())
+}
+
+// filterArtifacts returns the artifacts which match the configured filter.
+func filterArtifacts(artifacts []parse.Artifact) ([]parse.Artifact, error) {
+	where, err := whereFilter()
+	if err != nil || where == nil {
+		return artifacts, err
+	}
+
+	matches := where.Apply(artifacts)
+
+	logger.LogInfo(fmt.Sprintf("Found %d of %d artifact files matching the filter", len(matches), len(artifacts)))
+
+	return matches, nil
+}
+
+// filterWalk wraps `fn` so that it's only called with artifacts which match
+// the configured filter.
+func filterWalk(fn func(parse.Artifact) error) (func(parse.Artifact) error, error) {
+	where, err := whereFilter()
+	if err != nil || where == nil {
+		return fn, err
+	}
+
+	return func(artifact parse.Artifact) error {
+		if !where.Match(artifact) {
+			return nil
+		}
+
+		return fn(artifact)
+	}, nil
+}
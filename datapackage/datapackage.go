@@ -0,0 +1,223 @@
+package datapackage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/table"
+)
+
+const (
+	// DescriptorFile is the name of the file which describes the package.
+	DescriptorFile = "datapackage.json"
+
+	packageName    = "ace-archive"
+	packageTitle   = "Ace Archive"
+	packageProfile = "tabular-data-package"
+
+	resourceProfile  = "tabular-data-resource"
+	descriptorIndent = "  "
+)
+
+// tables are the tables in the package, each of which is a CSV resource.
+var tables = []table.Name{table.Artifacts, table.Files, table.People, table.Links}
+
+// descriptions describes the rows of each table.
+var descriptions = map[table.Name]string{
+	table.Artifacts: "The most recent version of each artifact",
+	table.Files:     "The files in each artifact",
+	table.People:    "The people associated with each artifact",
+	table.Links:     "The links in each artifact",
+}
+
+// Descriptor is a Frictionless Data Package descriptor.
+type Descriptor struct {
+	Profile   string     `json:"profile"`
+	Name      string     `json:"name"`
+	Title     string     `json:"title"`
+	Created   string     `json:"created"`
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is a CSV file in the package.
+type Resource struct {
+	Profile     string `json:"profile"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Format      string `json:"format"`
+	MediaType   string `json:"mediatype"`
+	Encoding    string `json:"encoding"`
+	Schema      Schema `json:"schema"`
+}
+
+// Schema is the Table Schema of a resource.
+type Schema struct {
+	Fields      []Field      `json:"fields"`
+	PrimaryKey  []string     `json:"primaryKey,omitempty"`
+	ForeignKeys []ForeignKey `json:"foreignKeys,omitempty"`
+}
+
+// Field is a column in a Table Schema.
+type Field struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description"`
+}
+
+// ForeignKey is a reference from a column in one resource to a column in
+// another.
+type ForeignKey struct {
+	Fields    []string  `json:"fields"`
+	Reference Reference `json:"reference"`
+}
+
+// Reference is the target of a foreign key.
+type Reference struct {
+	Resource string   `json:"resource"`
+	Fields   []string `json:"fields"`
+}
+
+func resourcePath(name table.Name) string {
+	return fmt.Sprintf("%s.csv", name)
+}
+
+func newSchema(name table.Name) Schema {
+	tableFields := table.Fields(name)
+	fields := make([]Field, len(tableFields))
+
+	for i, field := range tableFields {
+		fields[i] = Field{Name: field.Name, Type: string(field.Type), Format: "", Description: field.Description}
+
+		// Commit dates keep the offset of the commit's time zone, so they
+		// aren't always in UTC like the default format expects.
+		if field.Type == table.TypeDatetime {
+			fields[i].Format = "any"
+		}
+	}
+
+	if name == table.Artifacts {
+		return Schema{Fields: fields, PrimaryKey: []string{"slug"}, ForeignKeys: nil}
+	}
+
+	return Schema{
+		Fields:     fields,
+		PrimaryKey: nil,
+		ForeignKeys: []ForeignKey{{
+			Fields:    []string{"slug"},
+			Reference: Reference{Resource: string(table.Artifacts), Fields: []string{"slug"}},
+		}},
+	}
+}
+
+// NewDescriptor returns the descriptor of the package.
+func NewDescriptor() Descriptor {
+	resources := make([]Resource, len(tables))
+
+	for i, name := range tables {
+		resources[i] = Resource{
+			Profile:     resourceProfile,
+			Name:        string(name),
+			Path:        resourcePath(name),
+			Description: descriptions[name],
+			Format:      "csv",
+			MediaType:   "text/csv",
+			Encoding:    "utf-8",
+			Schema:      newSchema(name),
+		}
+	}
+
+	return Descriptor{
+		Profile:   packageProfile,
+		Name:      packageName,
+		Title:     packageTitle,
+		Created:   time.Now().UTC().Format(time.RFC3339),
+		Resources: resources,
+	}
+}
+
+// writeFile writes the file `name` in `dir` with `write`.
+func writeFile(dir, name string, write func(w io.Writer) error) (err error) {
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	return write(file)
+}
+
+func writeResource(w io.Writer, name table.Name, artifacts []parse.Artifact) error {
+	header, rows, err := table.Build(name, artifacts, table.Columns(name))
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// Write writes a Frictionless Data Package of the most recent version of each
+// artifact in `artifacts` to the directory `dir`, with a CSV resource for
+// each table and a `datapackage.json` which describes them. The rows are
+// sorted by slug.
+func Write(dir string, artifacts []parse.Artifact) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	latest := parse.Latest(artifacts)
+
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].Slug < latest[j].Slug
+	})
+
+	for _, name := range tables {
+		name := name
+
+		if err := writeFile(dir, resourcePath(name), func(w io.Writer) error {
+			return writeResource(w, name, latest)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFile(dir, DescriptorFile, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", descriptorIndent)
+
+		return encoder.Encode(NewDescriptor())
+	}); err != nil {
+		return err
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("Wrote a data package of %d artifacts to %s", len(latest), dir),
+		logger.Phase("export"),
+	)
+
+	return nil
+}
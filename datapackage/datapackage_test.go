@@ -0,0 +1,123 @@
+package datapackage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/table"
+)
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return records
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	artifacts := []parse.Artifact{
+		testutil.Artifact("zine", testutil.Date(1), nil),
+		testutil.Artifact("manifesto", testutil.Date(1), parse.GenericEntry{
+			string(parse.FieldFiles):  []interface{}{testutil.File("old.pdf", "", "")},
+			string(parse.FieldPeople): []interface{}{"Lisa Orlando"},
+		}),
+		testutil.Artifact("manifesto", testutil.Date(2), parse.GenericEntry{
+			string(parse.FieldFiles):  []interface{}{testutil.File("scan.pdf", "", ""), testutil.File("transcript.html", "", "")},
+			string(parse.FieldPeople): []interface{}{"Lisa Orlando"},
+		}),
+	}
+
+	if err := Write(dir, artifacts); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptorFile, err := os.ReadFile(filepath.Join(dir, DescriptorFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var descriptor Descriptor
+
+	if err := json.Unmarshal(descriptorFile, &descriptor); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(descriptor.Resources) != len(tables) {
+		t.Fatalf("datapackage.json has %d resources, want %d", len(descriptor.Resources), len(tables))
+	}
+
+	slugs := make(map[string]struct{})
+
+	for _, resource := range descriptor.Resources {
+		records := readCSV(t, filepath.Join(dir, resource.Path))
+
+		// The header is in the same order as the fields in the schema.
+		fieldNames := make([]string, len(resource.Schema.Fields))
+		for i, field := range resource.Schema.Fields {
+			fieldNames[i] = field.Name
+		}
+
+		if !reflect.DeepEqual(records[0], fieldNames) {
+			t.Errorf("%s has header %v, want %v", resource.Path, records[0], fieldNames)
+		}
+
+		if resource.Name == string(table.Artifacts) {
+			if !reflect.DeepEqual(resource.Schema.PrimaryKey, []string{"slug"}) || resource.Schema.ForeignKeys != nil {
+				t.Errorf("artifacts has keys %+v", resource.Schema)
+			}
+
+			// Only the most recent version of each artifact is included,
+			// sorted by slug.
+			for _, record := range records[1:] {
+				slugs[record[0]] = struct{}{}
+			}
+
+			if len(records) != 3 || records[1][0] != "manifesto" || records[2][0] != "zine" {
+				t.Errorf("artifacts.csv = %v, want one row per artifact", records)
+			}
+
+			continue
+		}
+
+		wantForeignKeys := []ForeignKey{{
+			Fields:    []string{"slug"},
+			Reference: Reference{Resource: string(table.Artifacts), Fields: []string{"slug"}},
+		}}
+
+		if resource.Schema.PrimaryKey != nil || !reflect.DeepEqual(resource.Schema.ForeignKeys, wantForeignKeys) {
+			t.Errorf("%s has keys %+v, want a foreign key to artifacts", resource.Name, resource.Schema)
+		}
+	}
+
+	// Every row refers to an artifact in the artifacts table.
+	for _, name := range tables[1:] {
+		for _, record := range readCSV(t, filepath.Join(dir, resourcePath(name)))[1:] {
+			if _, exists := slugs[record[0]]; !exists {
+				t.Errorf("%s has a row for %q, which isn't in artifacts.csv", name, record[0])
+			}
+		}
+	}
+
+	files := readCSV(t, filepath.Join(dir, resourcePath(table.Files)))
+	if len(files) != 3 {
+		t.Errorf("files.csv = %v, want the files in the most recent version", files)
+	}
+}
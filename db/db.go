@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+
+	// This is a pure-Go SQLite driver, so we can build without cgo.
+	_ "modernc.org/sqlite"
+)
+
+const driverName = "sqlite"
+
+type artifactSummary struct {
+	latestRevisionID int64
+	latestDate       *time.Time
+	firstSeen        *parse.ArtifactCommit
+	lastModified     *parse.ArtifactCommit
+	revisionCount    int
+}
+
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullableInt(value int, valid bool) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: valid}
+}
+
+func commitRev(commit *parse.ArtifactCommit) sql.NullString {
+	if commit == nil {
+		return sql.NullString{}
+	}
+
+	return nullableString(commit.Rev)
+}
+
+func commitDate(commit *parse.ArtifactCommit) sql.NullString {
+	if commit == nil {
+		return sql.NullString{}
+	}
+
+	return nullableString(commit.Date.Format(time.RFC3339))
+}
+
+func insertRevision(ctx context.Context, tx *sql.Tx, artifact parse.Artifact) (int64, error) {
+	entry := artifact.Entry
+
+	version, hasVersion := entry.Int(parse.FieldVersion)
+	fromYear, hasFromYear := entry.Int(parse.FieldFromYear)
+	toYear, hasToYear := entry.Int(parse.FieldToYear)
+
+	result, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO revisions (
+			slug, path, rev, date, version, title, title_plain, title_html, sort_key, description, long_description,
+			from_year, to_year
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		artifact.Slug,
+		artifact.Path,
+		commitRev(artifact.Commit),
+		commitDate(artifact.Commit),
+		nullableInt(version, hasVersion),
+		nullableString(entry.String(parse.FieldTitle)),
+		nullableString(artifact.TitlePlain),
+		nullableString(artifact.TitleHTML),
+		nullableString(artifact.SortKey),
+		nullableString(strings.TrimSpace(entry.String(parse.FieldDescription))),
+		nullableString(strings.TrimSpace(entry.String(parse.FieldLongDescription))),
+		nullableInt(fromYear, hasFromYear),
+		nullableInt(toYear, hasToYear),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	revisionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for position, file := range entry.Objects(parse.FieldFiles) {
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO files (revision_id, position, name, filename, media_type, cid) VALUES (?, ?, ?, ?, ?, ?)",
+			revisionID,
+			position,
+			nullableString(file.String(parse.FieldFileName)),
+			nullableString(file.String(parse.FieldFileFilename)),
+			nullableString(file.String(parse.FieldFileMediaType)),
+			nullableString(file.String(parse.FieldFileCid)),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	for position, link := range entry.Objects(parse.FieldLinks) {
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO links (revision_id, position, name, url) VALUES (?, ?, ?, ?)",
+			revisionID,
+			position,
+			nullableString(link.String(parse.FieldLinkName)),
+			nullableString(link.String(parse.FieldLinkURL)),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	for position, person := range entry.Strings(parse.FieldPeople) {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO people (revision_id, position, name) VALUES (?, ?, ?)", revisionID, position, person); err != nil {
+			return 0, err
+		}
+	}
+
+	for position, identity := range entry.Strings(parse.FieldIdentities) {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO identities (revision_id, position, name) VALUES (?, ?, ?)", revisionID, position, identity); err != nil {
+			return 0, err
+		}
+	}
+
+	// Past versions of an artifact file may not have passed validation, so we
+	// ignore duplicates rather than failing.
+	for _, decade := range entry.Ints(parse.FieldDecades) {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO decades (revision_id, decade) VALUES (?, ?)", revisionID, decade); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, alias := range entry.Strings(parse.FieldAliases) {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO aliases (revision_id, alias) VALUES (?, ?)", revisionID, alias); err != nil {
+			return 0, err
+		}
+	}
+
+	return revisionID, nil
+}
+
+// summarize updates the summary of the artifact a revision belongs to.
+func summarize(summary *artifactSummary, revisionID int64, artifact parse.Artifact) {
+	summary.revisionCount++
+
+	if artifact.Commit == nil {
+		summary.latestRevisionID = revisionID
+		return
+	}
+
+	date := artifact.Commit.Date
+
+	if summary.latestDate == nil || date.After(*summary.latestDate) {
+		summary.latestRevisionID = revisionID
+		summary.latestDate = &date
+		summary.lastModified = artifact.Commit
+	}
+
+	if summary.firstSeen == nil || date.Before(summary.firstSeen.Date) {
+		summary.firstSeen = artifact.Commit
+	}
+}
+
+func populate(ctx context.Context, tx *sql.Tx, artifacts []parse.Artifact) error {
+	if _, err := tx.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return err
+	}
+
+	summaries := make(map[string]*artifactSummary)
+	slugs := make([]string, 0)
+
+	for _, artifact := range artifacts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		revisionID, err := insertRevision(ctx, tx, artifact)
+		if err != nil {
+			return err
+		}
+
+		summary, exists := summaries[artifact.Slug]
+		if !exists {
+			summary = &artifactSummary{}
+			summaries[artifact.Slug] = summary
+			slugs = append(slugs, artifact.Slug)
+		}
+
+		summarize(summary, revisionID, artifact)
+	}
+
+	for _, slug := range slugs {
+		summary := summaries[slug]
+
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO artifacts (
+				slug, latest_revision_id, first_seen_rev, first_seen_date, last_modified_rev, last_modified_date, revision_count
+			) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			slug,
+			summary.latestRevisionID,
+			commitRev(summary.firstSeen),
+			commitDate(summary.firstSeen),
+			commitRev(summary.lastModified),
+			commitDate(summary.lastModified),
+			summary.revisionCount,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export writes a SQLite database containing `artifacts` to the file at
+// `path`, replacing it if it already exists.
+func Export(ctx context.Context, path string, artifacts []parse.Artifact) (err error) {
+	startTime := time.Now()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	database, err := sql.Open(driverName, path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := populate(ctx, tx, artifacts); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w: %s", err, rollbackErr.Error())
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("Wrote %d artifact files to the database: %s", len(artifacts), path),
+		logger.Phase("export"),
+		logger.Duration(time.Since(startTime)),
+	)
+
+	return nil
+}
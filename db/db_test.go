@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func testVersion(slug string, day int, decades []interface{}, cids ...string) parse.Artifact {
+	files := make([]interface{}, len(cids))
+
+	for i, fileCid := range cids {
+		files[i] = map[string]interface{}{"name": fileCid, "filename": fileCid + ".pdf", "cid": fileCid}
+	}
+
+	date := time.Date(2022, time.January, day, 0, 0, 0, 0, time.UTC)
+
+	return parse.Artifact{
+		Path:   "artifacts/" + slug + parse.ArtifactFileExtension,
+		Slug:   slug,
+		Commit: &parse.ArtifactCommit{Rev: slug + "-" + date.Format("2006-01-02"), Date: date},
+		Entry: parse.GenericEntry{
+			"version": 3,
+			"title":   "The " + slug,
+			"files":   files,
+			"decades": decades,
+		},
+	}
+}
+
+// exportTestHistory exports a small history to a temporary database and
+// opens it.
+func exportTestHistory(t *testing.T) *sql.DB {
+	t.Helper()
+
+	history := []parse.Artifact{
+		// A file was added to the manifesto, and then the first file was
+		// replaced.
+		testVersion("manifesto", 4, []interface{}{1970}, "bafy-replaced-scan"),
+		testVersion("manifesto", 3, []interface{}{1970}, "bafy-scan", "bafy-transcript"),
+		testVersion("zine", 2, []interface{}{1990, 2000}, "bafy-zine"),
+		testVersion("interview", 2, []interface{}{1990}),
+		testVersion("manifesto", 1, []interface{}{1970}, "bafy-scan"),
+	}
+
+	path := filepath.Join(t.TempDir(), "artifacts.db")
+
+	if err := Export(context.Background(), path, history); err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := sql.Open(driverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { database.Close() })
+
+	return database
+}
+
+func TestExportCidsOfSlug(t *testing.T) {
+	database := exportTestHistory(t)
+
+	rows, err := database.Query(`
+		SELECT DISTINCT files.cid FROM files
+		JOIN revisions ON revisions.id = files.revision_id
+		WHERE revisions.slug = ?
+		ORDER BY files.cid
+	`, "manifesto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var cids []string
+
+	for rows.Next() {
+		var fileCid string
+
+		if err := rows.Scan(&fileCid); err != nil {
+			t.Fatal(err)
+		}
+
+		cids = append(cids, fileCid)
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"bafy-replaced-scan", "bafy-scan", "bafy-transcript"}; !reflect.DeepEqual(cids, want) {
+		t.Errorf("CIDs ever attached to the manifesto = %v, want %v", cids, want)
+	}
+}
+
+func TestExportArtifactsPerDecade(t *testing.T) {
+	database := exportTestHistory(t)
+
+	// Only the most recent version of each artifact is counted.
+	rows, err := database.Query(`
+		SELECT decades.decade, COUNT(*) FROM artifacts
+		JOIN decades ON decades.revision_id = artifacts.latest_revision_id
+		GROUP BY decades.decade
+		ORDER BY decades.decade
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+
+	for rows.Next() {
+		var decade, count int
+
+		if err := rows.Scan(&decade, &count); err != nil {
+			t.Fatal(err)
+		}
+
+		counts[decade] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[int]int{1970: 1, 1990: 2, 2000: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("artifacts per decade = %v, want %v", counts, want)
+	}
+}
+
+func TestExportArtifacts(t *testing.T) {
+	database := exportTestHistory(t)
+
+	var version int
+
+	if err := database.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+
+	if version != schemaVersion {
+		t.Errorf("user_version = %d, want %d", version, schemaVersion)
+	}
+
+	var firstSeen, lastModified, latestRev string
+
+	var revisionCount int
+
+	if err := database.QueryRow(`
+		SELECT artifacts.first_seen_rev, artifacts.last_modified_rev, artifacts.revision_count, revisions.rev
+		FROM artifacts
+		JOIN revisions ON revisions.id = artifacts.latest_revision_id
+		WHERE artifacts.slug = ?
+	`, "manifesto").Scan(&firstSeen, &lastModified, &revisionCount, &latestRev); err != nil {
+		t.Fatal(err)
+	}
+
+	if firstSeen != "manifesto-2022-01-01" || lastModified != "manifesto-2022-01-04" || latestRev != lastModified || revisionCount != 3 {
+		t.Errorf(
+			"first seen = %s, last modified = %s, latest revision = %s, revisions = %d",
+			firstSeen, lastModified, latestRev, revisionCount,
+		)
+	}
+}
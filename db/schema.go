@@ -0,0 +1,91 @@
+package db
+
+// schemaVersion is stored in `PRAGMA user_version` so that consumers can tell
+// which version of this schema a database uses. Bump it whenever the schema
+// changes.
+const schemaVersion = 2
+
+// schema is the schema of the exported database. Every version of every
+// artifact is a row in `revisions`, and the other tables hang off of it.
+// `artifacts` has one row per slug which points to its latest revision.
+const schema = `
+CREATE TABLE revisions (
+	id INTEGER PRIMARY KEY,
+	slug TEXT NOT NULL,
+	path TEXT NOT NULL,
+	rev TEXT,
+	date TEXT,
+	version INTEGER,
+	title TEXT,
+	title_plain TEXT,
+	title_html TEXT,
+	sort_key TEXT,
+	description TEXT,
+	long_description TEXT,
+	from_year INTEGER,
+	to_year INTEGER
+);
+
+CREATE TABLE artifacts (
+	slug TEXT PRIMARY KEY,
+	latest_revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	first_seen_rev TEXT,
+	first_seen_date TEXT,
+	last_modified_rev TEXT,
+	last_modified_date TEXT,
+	revision_count INTEGER NOT NULL
+);
+
+CREATE TABLE files (
+	revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	position INTEGER NOT NULL,
+	name TEXT,
+	filename TEXT,
+	media_type TEXT,
+	cid TEXT,
+	PRIMARY KEY (revision_id, position)
+);
+
+CREATE TABLE links (
+	revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	position INTEGER NOT NULL,
+	name TEXT,
+	url TEXT,
+	PRIMARY KEY (revision_id, position)
+);
+
+CREATE TABLE people (
+	revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	PRIMARY KEY (revision_id, position)
+);
+
+CREATE TABLE identities (
+	revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	PRIMARY KEY (revision_id, position)
+);
+
+CREATE TABLE decades (
+	revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	decade INTEGER NOT NULL,
+	PRIMARY KEY (revision_id, decade)
+);
+
+CREATE TABLE aliases (
+	revision_id INTEGER NOT NULL REFERENCES revisions (id),
+	alias TEXT NOT NULL,
+	PRIMARY KEY (revision_id, alias)
+);
+
+CREATE INDEX revisions_slug ON revisions (slug);
+CREATE INDEX revisions_date ON revisions (date);
+CREATE INDEX revisions_sort_key ON revisions (sort_key);
+CREATE INDEX files_cid ON files (cid);
+CREATE INDEX people_name ON people (name);
+CREATE INDEX identities_name ON identities (name);
+CREATE INDEX decades_decade ON decades (decade);
+CREATE INDEX aliases_alias ON aliases (alias);
+`
@@ -2,10 +2,12 @@ package dir
 
 import (
 	"context"
-	"sort"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/acearchive/artifact-action/client"
+	"github.com/acearchive/artifact-action/logger"
 	"github.com/acearchive/artifact-action/parse"
 	"github.com/ipfs/go-cid"
 	dag "github.com/ipfs/go-merkledag"
@@ -22,14 +24,12 @@ type fileMapType = map[string]cid.Cid
 // artifactMapType is a map of artifact slugs to maps of their files.
 type artifactMapType = map[string]fileMapType
 
+// getLatestFiles returns the most recent file with each file name in each
+// artifact. It doesn't modify `artifacts`.
 func getLatestFiles(artifacts []parse.Artifact) artifactMapType {
 	artifactMap := make(artifactMapType, len(artifacts))
 
-	sort.Slice(artifacts, func(i, j int) bool {
-		return artifacts[j].Commit.Date.Before(artifacts[i].Commit.Date)
-	})
-
-	for _, artifact := range artifacts {
+	for _, artifact := range parse.NewestFirst(artifacts) {
 		entry := struct {
 			Files []struct {
 				Filename string `json:"filename"`
@@ -75,6 +75,8 @@ func getLatestFiles(artifacts []parse.Artifact) artifactMapType {
 // Build builds a directory containing the most recent file with a given file
 // name in each artifact.
 func Build(ctx context.Context, artifacts []parse.Artifact) (cid.Cid, error) {
+	startTime := time.Now()
+
 	ipfsClientGuard, err := client.New()
 	if err != nil {
 		return cid.Undef, err
@@ -91,6 +93,10 @@ func Build(ctx context.Context, artifacts []parse.Artifact) (cid.Cid, error) {
 	rootDir.SetCidBuilder(DefaultCidPrefix())
 
 	for artifactSlug, artifactFiles := range artifactMap {
+		if err := ctx.Err(); err != nil {
+			return cid.Undef, err
+		}
+
 		// Unsure why this is failing since it doesn't take a context.
 		//nolint:contextcheck
 		artifactDir := unixfs.NewDirectory(ipfsClient.Dag())
@@ -119,6 +125,13 @@ func Build(ctx context.Context, artifacts []parse.Artifact) (cid.Cid, error) {
 		if err := ipfsClient.Dag().Add(ctx, artifactNode); err != nil {
 			return cid.Undef, err
 		}
+
+		logger.LogDebug(
+			fmt.Sprintf("Added the directory for %s: /ipfs/%s", artifactSlug, artifactNode.Cid().String()),
+			logger.Slug(artifactSlug),
+			logger.Cid(artifactNode.Cid()),
+			logger.Phase("build"),
+		)
 	}
 
 	rootNode, err := rootDir.GetNode()
@@ -130,5 +143,12 @@ func Build(ctx context.Context, artifacts []parse.Artifact) (cid.Cid, error) {
 		return cid.Undef, err
 	}
 
+	logger.LogInfo(
+		fmt.Sprintf("Built the root directory: /ipfs/%s", rootNode.Cid().String()),
+		logger.Cid(rootNode.Cid()),
+		logger.Phase("build"),
+		logger.Duration(time.Since(startTime)),
+	)
+
 	return rootNode.Cid(), nil
 }
@@ -0,0 +1,91 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	ID        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Updated   string       `xml:"updated"`
+	Published string       `xml:"published,omitempty"`
+	Authors   []atomPerson `xml:"author"`
+	Summary   string       `xml:"summary,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// WriteAtom writes an Atom feed of the events in the history `artifacts` to
+// `w`.
+func WriteAtom(w io.Writer, artifacts []parse.Artifact, opts Options) error {
+	events := Events(artifacts, opts)
+
+	feed := atomFeed{
+		Xmlns:   atomNamespace,
+		Title:   opts.Title,
+		ID:      opts.SiteURL,
+		Links:   []atomLink{{Href: opts.SiteURL, Rel: "alternate"}},
+		Updated: updated(events).Format(time.RFC3339),
+		Author:  atomPerson{Name: opts.Title},
+		Entries: make([]atomEntry, len(events)),
+	}
+
+	for i, event := range events {
+		authors := make([]atomPerson, len(event.People))
+
+		for personIndex, person := range event.People {
+			authors[personIndex] = atomPerson{Name: person}
+		}
+
+		entry := atomEntry{
+			Title:     event.title(),
+			ID:        opts.id(event),
+			Link:      atomLink{Href: opts.link(event.Slug), Rel: "alternate"},
+			Updated:   event.Commit.Date.Format(time.RFC3339),
+			Published: "",
+			Authors:   authors,
+			Summary:   event.summary(),
+		}
+
+		if event.Kind == EventAdded {
+			entry.Published = entry.Updated
+		}
+
+		feed.Entries[i] = entry
+	}
+
+	marshalledFeed, err := xml.MarshalIndent(feed, "", indent)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, marshalledFeed)
+
+	return err
+}
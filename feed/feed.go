@@ -0,0 +1,230 @@
+package feed
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/markdown"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// EventKind is what happened to an artifact in a feed entry.
+type EventKind string
+
+const (
+	// EventAdded is when an artifact first appeared.
+	EventAdded EventKind = "added"
+
+	// EventUpdated is when files were added to an existing artifact.
+	EventUpdated EventKind = "updated"
+)
+
+const indent = "  "
+
+// Options configures a feed.
+type Options struct {
+	// SiteURL is the base URL of the site, which the slug of each artifact is
+	// appended to.
+	SiteURL string
+
+	// Title is the title of the feed.
+	Title string
+
+	// Updates is whether to include an entry each time files are added to an
+	// artifact, in addition to when it first appears.
+	Updates bool
+
+	// Limit is the maximum number of entries, or 0 for no limit.
+	Limit int
+
+	// Current is the slug of each artifact at HEAD, as returned by
+	// `parse.HeadSlugs`. Artifacts which aren't current are left out, unless
+	// this is nil.
+	Current map[string]struct{}
+
+	// Renames is the slug each artifact was renamed to, as returned by
+	// `parse.Renames`, so that renaming an artifact isn't mistaken for adding
+	// a new one.
+	Renames map[string]string
+}
+
+// Event is an entry in a feed.
+type Event struct {
+	Kind EventKind
+
+	// Slug is the current slug of the artifact, which its link points to.
+	Slug string
+
+	// AddedAs is the slug the artifact had when it was added, which
+	// identifies it in the feed so that renaming it doesn't look like a new
+	// entry to subscribers.
+	AddedAs string
+
+	Title       string
+	Description string
+	People      []string
+	Commit      parse.ArtifactCommit
+
+	// NewFiles is the names of the files which were added, for updates.
+	NewFiles []string
+}
+
+// link returns the URL of the artifact with the given slug.
+func (o Options) link(slug string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(o.SiteURL, "/"), slug)
+}
+
+// id returns a unique, stable identifier for the event.
+func (o Options) id(event Event) string {
+	if event.Kind == EventAdded {
+		return o.link(event.AddedAs)
+	}
+
+	return fmt.Sprintf("%s#%s", o.link(event.AddedAs), event.Commit.Rev)
+}
+
+// currentSlug returns the slug that the artifact with the slug `slug` has
+// now, and whether it's still in the archive.
+func (o Options) currentSlug(slug string) (string, bool) {
+	if o.Current == nil {
+		return slug, true
+	}
+
+	return parse.CurrentSlug(slug, o.Renames, o.Current)
+}
+
+func (e Event) summary() string {
+	if e.Kind == EventAdded {
+		return e.Description
+	}
+
+	return fmt.Sprintf("New files: %s", strings.Join(e.NewFiles, ", "))
+}
+
+func (e Event) title() string {
+	if e.Kind == EventAdded {
+		return e.Title
+	}
+
+	return fmt.Sprintf("Updated: %s", e.Title)
+}
+
+func filenames(entry parse.GenericEntry) []string {
+	files := entry.Objects(parse.FieldFiles)
+	names := make([]string, 0, len(files))
+
+	for _, file := range files {
+		if filename := strings.TrimSpace(file.String(parse.FieldFileFilename)); filename != "" {
+			names = append(names, filename)
+		}
+	}
+
+	return names
+}
+
+func newEvent(kind EventKind, slug, addedAs string, artifact parse.Artifact) Event {
+	return Event{
+		Kind:        kind,
+		Slug:        slug,
+		AddedAs:     addedAs,
+		Title:       artifact.TitlePlain,
+		Description: markdown.Plain(artifact.Entry.String(parse.FieldDescription)),
+		People:      artifact.Entry.Strings(parse.FieldPeople),
+		Commit:      *artifact.Commit,
+		NewFiles:    nil,
+	}
+}
+
+// lineages returns the versions of each artifact in `artifacts` by its current
+// slug, from least to most recent. The versions from before an artifact was
+// renamed are included, and versions which aren't from the history are
+// skipped.
+func lineages(artifacts []parse.Artifact, opts Options) map[string][]parse.Artifact {
+	versions := make(map[string][]parse.Artifact)
+
+	for _, artifact := range artifacts {
+		if artifact.Commit == nil {
+			continue
+		}
+
+		if slug, isCurrent := opts.currentSlug(artifact.Slug); isCurrent {
+			versions[slug] = append(versions[slug], artifact)
+		}
+	}
+
+	for _, lineage := range versions {
+		lineage := lineage
+
+		sort.SliceStable(lineage, func(i, j int) bool {
+			return lineage[i].Commit.Date.Before(lineage[j].Commit.Date)
+		})
+	}
+
+	return versions
+}
+
+// Events returns the events in the history `artifacts`, from most to least
+// recent. Artifacts which aren't from the history are skipped.
+func Events(artifacts []parse.Artifact, opts Options) []Event {
+	events := make([]Event, 0)
+
+	for slug, lineage := range lineages(artifacts, opts) {
+		var previousFiles map[string]struct{}
+
+		addedAs := lineage[0].Slug
+
+		for _, revision := range lineage {
+			currentFiles := filenames(revision.Entry)
+
+			if previousFiles == nil {
+				events = append(events, newEvent(EventAdded, slug, addedAs, revision))
+			} else if opts.Updates {
+				var newFiles []string
+
+				for _, filename := range currentFiles {
+					if _, exists := previousFiles[filename]; !exists {
+						newFiles = append(newFiles, filename)
+					}
+				}
+
+				if len(newFiles) > 0 {
+					event := newEvent(EventUpdated, slug, addedAs, revision)
+					event.NewFiles = newFiles
+					events = append(events, event)
+				}
+			}
+
+			previousFiles = make(map[string]struct{}, len(currentFiles))
+
+			for _, filename := range currentFiles {
+				previousFiles[filename] = struct{}{}
+			}
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Commit.Date.Equal(events[j].Commit.Date) {
+			return events[i].Commit.Date.After(events[j].Commit.Date)
+		}
+
+		return events[i].Slug < events[j].Slug
+	})
+
+	if opts.Limit > 0 && len(events) > opts.Limit {
+		events = events[:opts.Limit]
+	}
+
+	return events
+}
+
+// updated returns when the feed was last updated, which is the date of the
+// most recent event.
+func updated(events []Event) time.Time {
+	if len(events) == 0 {
+		return time.Unix(0, 0).UTC()
+	}
+
+	return events[0].Commit.Date
+}
@@ -0,0 +1,133 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func testArtifact(slug string, day int, filenames ...string) parse.Artifact {
+	files := make([]interface{}, len(filenames))
+
+	for i, filename := range filenames {
+		files[i] = testutil.File(filename, "", "")
+	}
+
+	return testutil.Artifact(slug, testutil.Date(day), parse.GenericEntry{
+		string(parse.FieldTitle):       "*" + slug + "*",
+		string(parse.FieldDescription): "About _" + slug + "_",
+		string(parse.FieldFiles):       files,
+	})
+}
+
+// eventSummary is the parts of an event which tests check.
+type eventSummary struct {
+	Kind     EventKind
+	Slug     string
+	AddedAs  string
+	Day      int
+	NewFiles []string
+}
+
+func summarize(events []Event) []eventSummary {
+	summaries := make([]eventSummary, len(events))
+
+	for i, event := range events {
+		summaries[i] = eventSummary{
+			Kind:     event.Kind,
+			Slug:     event.Slug,
+			AddedAs:  event.AddedAs,
+			Day:      event.Commit.Date.Day(),
+			NewFiles: event.NewFiles,
+		}
+	}
+
+	return summaries
+}
+
+func TestEvents(t *testing.T) {
+	artifacts := []parse.Artifact{
+		testArtifact("manifesto", 1, "scan.pdf"),
+		testArtifact("manifesto", 3, "scan.pdf", "transcript.html"),
+		testArtifact("the-zine", 2),
+		// The zine was renamed, and then files were added to it.
+		testArtifact("zine", 4),
+		testArtifact("zine", 5, "zine.pdf"),
+		testArtifact("deleted", 6),
+	}
+
+	opts := Options{
+		SiteURL: "https://example.com/artifacts",
+		Title:   "Test Archive",
+		Updates: true,
+		Limit:   0,
+		Current: map[string]struct{}{"manifesto": {}, "zine": {}},
+		Renames: map[string]string{"the-zine": "zine"},
+	}
+
+	want := []eventSummary{
+		{Kind: EventUpdated, Slug: "zine", AddedAs: "the-zine", Day: 5, NewFiles: []string{"zine.pdf"}},
+		{Kind: EventUpdated, Slug: "manifesto", AddedAs: "manifesto", Day: 3, NewFiles: []string{"transcript.html"}},
+		{Kind: EventAdded, Slug: "zine", AddedAs: "the-zine", Day: 2, NewFiles: nil},
+		{Kind: EventAdded, Slug: "manifesto", AddedAs: "manifesto", Day: 1, NewFiles: nil},
+	}
+
+	if got := summarize(Events(artifacts, opts)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Events() = %+v, want %+v", got, want)
+	}
+
+	opts.Updates = false
+	opts.Limit = 1
+
+	if got := summarize(Events(artifacts, opts)); !reflect.DeepEqual(got, want[2:3]) {
+		t.Errorf("Events() without updates = %+v, want %+v", got, want[2:3])
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	artifacts := []parse.Artifact{testArtifact("the-zine", 1), testArtifact("zine", 2)}
+
+	opts := Options{
+		SiteURL: "https://example.com/artifacts/",
+		Title:   "Test Archive",
+		Updates: false,
+		Limit:   0,
+		Current: map[string]struct{}{"zine": {}},
+		Renames: map[string]string{"the-zine": "zine"},
+	}
+
+	var buf bytes.Buffer
+
+	if err := WriteAtom(&buf, artifacts, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var got atomFeed
+
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1:\n%s", len(got.Entries), buf.String())
+	}
+
+	entry := got.Entries[0]
+
+	// The title and summary are plain text, without Markdown.
+	if entry.Title != "the-zine" || entry.Summary != "About the-zine" {
+		t.Errorf("title = %q, summary = %q", entry.Title, entry.Summary)
+	}
+
+	if entry.ID != "https://example.com/artifacts/the-zine" {
+		t.Errorf("id = %q, want the slug it was added as", entry.ID)
+	}
+
+	if entry.Link.Href != "https://example.com/artifacts/zine" {
+		t.Errorf("link = %q, want the current slug", entry.Link.Href)
+	}
+}
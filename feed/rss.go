@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// WriteRSS writes an RSS 2.0 feed of the events in the history `artifacts` to
+// `w`.
+func WriteRSS(w io.Writer, artifacts []parse.Artifact, opts Options) error {
+	events := Events(artifacts, opts)
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         opts.Title,
+			Link:          opts.SiteURL,
+			Description:   fmt.Sprintf("New artifacts in %s", opts.Title),
+			LastBuildDate: updated(events).Format(time.RFC1123Z),
+			Items:         make([]rssItem, len(events)),
+		},
+	}
+
+	for i, event := range events {
+		feed.Channel.Items[i] = rssItem{
+			Title: event.title(),
+			Link:  opts.link(event.Slug),
+			GUID: rssGUID{
+				IsPermaLink: event.Kind == EventAdded && event.AddedAs == event.Slug,
+				Value:       opts.id(event),
+			},
+			PubDate:     event.Commit.Date.Format(time.RFC1123Z),
+			Description: event.summary(),
+		}
+	}
+
+	marshalledFeed, err := xml.MarshalIndent(feed, "", indent)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, marshalledFeed)
+
+	return err
+}
@@ -0,0 +1,233 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+var ErrInvalidFilter = errors.New("this is not a valid filter")
+
+// node is a node in the syntax tree of a filter expression.
+type node interface {
+	eval(artifact parse.Artifact) interface{}
+}
+
+type andNode struct{ left, right node }
+
+type orNode struct{ left, right node }
+
+type notNode struct{ operand node }
+
+type comparisonNode struct {
+	left, right node
+	operator    string
+}
+
+type fieldNode struct{ path []string }
+
+type literalNode struct{ value interface{} }
+
+func (n andNode) eval(artifact parse.Artifact) interface{} {
+	return truthy(n.left.eval(artifact)) && truthy(n.right.eval(artifact))
+}
+
+func (n orNode) eval(artifact parse.Artifact) interface{} {
+	return truthy(n.left.eval(artifact)) || truthy(n.right.eval(artifact))
+}
+
+func (n notNode) eval(artifact parse.Artifact) interface{} {
+	return !truthy(n.operand.eval(artifact))
+}
+
+func (n comparisonNode) eval(artifact parse.Artifact) interface{} {
+	return compare(n.left.eval(artifact), n.operator, n.right.eval(artifact))
+}
+
+func (n literalNode) eval(parse.Artifact) interface{} {
+	return n.value
+}
+
+// eval returns the value of a field. The fields `slug`, `path`, `rev`, and
+// `date` come from the artifact itself, and every other field comes from its
+// entry. A path like `files.mediaType` returns the value of `mediaType` for
+// each object in `files`.
+func (n fieldNode) eval(artifact parse.Artifact) interface{} {
+	if len(n.path) == 1 {
+		switch n.path[0] {
+		case "slug":
+			return artifact.Slug
+		case "path":
+			return artifact.Path
+		case "titlePlain":
+			return artifact.TitlePlain
+		case "sortKey":
+			return artifact.SortKey
+		case "rev":
+			if artifact.Commit == nil {
+				return nil
+			}
+
+			return artifact.Commit.Rev
+		case "date":
+			if artifact.Commit == nil {
+				return nil
+			}
+
+			return artifact.Commit.Date.Format(time.RFC3339)
+		}
+	}
+
+	return lookup(map[string]interface{}(artifact.Entry), n.path)
+}
+
+func lookup(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return normalize(value)
+	}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		return lookup(typedValue[path[0]], path[1:])
+	case parse.GenericEntry:
+		return lookup(typedValue[path[0]], path[1:])
+	case []interface{}:
+		values := make([]interface{}, 0, len(typedValue))
+
+		for _, element := range typedValue {
+			if elementValue := lookup(element, path); elementValue != nil {
+				values = append(values, elementValue)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// normalize converts every number to a float64 so they can be compared
+// regardless of how they were parsed.
+func normalize(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case int:
+		return float64(typedValue)
+	case int64:
+		return float64(typedValue)
+	case []interface{}:
+		values := make([]interface{}, len(typedValue))
+
+		for i, element := range typedValue {
+			values[i] = normalize(element)
+		}
+
+		return values
+	default:
+		return value
+	}
+}
+
+func truthy(value interface{}) bool {
+	switch typedValue := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typedValue
+	case string:
+		return typedValue != ""
+	case float64:
+		return typedValue != 0
+	case []interface{}:
+		return len(typedValue) != 0
+	default:
+		return true
+	}
+}
+
+func number(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case float64:
+		return typedValue, true
+	case string:
+		parsedValue, err := strconv.ParseFloat(strings.TrimSpace(typedValue), 64)
+		return parsedValue, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// order returns -1, 0, or 1 depending on whether `left` is less than, equal
+// to, or greater than `right`. Values are compared as numbers if they both
+// are or can be parsed as one, and as strings otherwise.
+func order(left, right interface{}) int {
+	leftNumber, leftIsNumber := number(left)
+	rightNumber, rightIsNumber := number(right)
+
+	if leftIsNumber && rightIsNumber {
+		switch {
+		case leftNumber < rightNumber:
+			return -1
+		case leftNumber > rightNumber:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+}
+
+// compare applies a comparison operator. A list on the left matches if any of
+// its elements does, except with `!=`, which matches if none of its elements
+// are equal. A string on the left contains any of its substrings. A missing
+// value never matches, except with `!=`.
+func compare(left interface{}, operator string, right interface{}) bool {
+	if operator == "!=" {
+		return !compare(left, "==", right)
+	}
+
+	if left == nil || right == nil {
+		return false
+	}
+
+	if values, isList := left.([]interface{}); isList {
+		// A list contains a value if one of its elements is equal to it, not
+		// if one of its elements contains it.
+		if operator == operatorContains {
+			operator = "=="
+		}
+
+		for _, value := range values {
+			if compare(value, operator, right) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch operator {
+	case operatorContains:
+		if leftStr, isString := left.(string); isString {
+			return strings.Contains(leftStr, fmt.Sprint(right))
+		}
+
+		return order(left, right) == 0
+	case "==":
+		return order(left, right) == 0
+	case "<":
+		return order(left, right) < 0
+	case "<=":
+		return order(left, right) <= 0
+	case ">":
+		return order(left, right) > 0
+	case ">=":
+		return order(left, right) >= 0
+	default:
+		return false
+	}
+}
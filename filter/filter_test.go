@@ -0,0 +1,152 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+var testArtifacts = []parse.Artifact{
+	{
+		Path:       "artifacts/orlando-the-asexual-manifesto.md",
+		Slug:       "orlando-the-asexual-manifesto",
+		TitlePlain: "The Asexual Manifesto",
+		SortKey:    "asexual manifesto",
+		Commit:     &parse.ArtifactCommit{Rev: "aaaa", Date: time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		Entry: parse.GenericEntry{
+			"title":      "*The Asexual Manifesto*",
+			"identities": []interface{}{"asexual"},
+			"people":     []interface{}{"Lisa Orlando", "Barbara Getz"},
+			"fromYear":   1972,
+			"decades":    []interface{}{1970},
+			"files": []interface{}{
+				map[string]interface{}{"name": "Digital Scan", "mediaType": "application/pdf"},
+				map[string]interface{}{"name": "Transcript", "mediaType": "text/html"},
+			},
+		},
+	},
+	{
+		Path:       "artifacts/a-zine.md",
+		Slug:       "a-zine",
+		TitlePlain: "A Zine About Aces",
+		SortKey:    "zine about aces",
+		Commit:     &parse.ArtifactCommit{Rev: "bbbb", Date: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		Entry: parse.GenericEntry{
+			"title":      "A _Zine_ About Aces",
+			"identities": []interface{}{"asexual", "aromantic"},
+			"people":     []interface{}{},
+			"fromYear":   1995,
+			"toYear":     2003,
+			"decades":    []interface{}{1990, 2000},
+			"files":      []interface{}{},
+		},
+	},
+	{
+		Path:       "artifacts/from-tree.md",
+		Slug:       "from-tree",
+		TitlePlain: "From the tree",
+		SortKey:    "from the tree",
+		Commit:     nil,
+		Entry: parse.GenericEntry{
+			"title":      "From the tree",
+			"identities": []interface{}{"aromantic"},
+			"fromYear":   2010,
+			"decades":    []interface{}{2010},
+		},
+	},
+}
+
+func matchingSlugs(t *testing.T, expression string) []string {
+	t.Helper()
+
+	f, err := Parse(expression)
+	if err != nil {
+		t.Fatalf("Parse(%q) = %v", expression, err)
+	}
+
+	slugs := make([]string, 0)
+
+	for _, artifact := range f.Apply(testArtifacts) {
+		slugs = append(slugs, artifact.Slug)
+	}
+
+	return slugs
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       []string
+	}{
+		{`identities contains "asexual" && fromYear < 1980`, []string{"orlando-the-asexual-manifesto"}},
+		{`identities contains "aromantic"`, []string{"a-zine", "from-tree"}},
+		{`identities contains "sexual"`, []string{}},
+		{`title contains "Zine"`, []string{"a-zine"}},
+		{`slug == "a-zine"`, []string{"a-zine"}},
+		{`slug != "a-zine"`, []string{"orlando-the-asexual-manifesto", "from-tree"}},
+		{`fromYear >= 1995`, []string{"a-zine", "from-tree"}},
+		{`fromYear > 1995`, []string{"from-tree"}},
+		{`fromYear <= 1972`, []string{"orlando-the-asexual-manifesto"}},
+		{`fromYear == 1972.0`, []string{"orlando-the-asexual-manifesto"}},
+		{`decades == 2000`, []string{"a-zine"}},
+		{`decades != 1970`, []string{"a-zine", "from-tree"}},
+		{`files.mediaType == "text/html"`, []string{"orlando-the-asexual-manifesto"}},
+		{`files.name contains "Scan"`, []string{}},
+		{`toYear`, []string{"a-zine"}},
+		{`!toYear`, []string{"orlando-the-asexual-manifesto", "from-tree"}},
+		{`toYear < 2010`, []string{"a-zine"}},
+		{`!(toYear < 2010)`, []string{"orlando-the-asexual-manifesto", "from-tree"}},
+		{`people`, []string{"orlando-the-asexual-manifesto"}},
+		{`titlePlain == "The Asexual Manifesto"`, []string{"orlando-the-asexual-manifesto"}},
+		{`sortKey < "b"`, []string{"orlando-the-asexual-manifesto"}},
+		{`date >= "2022-06-01"`, []string{"a-zine"}},
+		{`rev == "aaaa"`, []string{"orlando-the-asexual-manifesto"}},
+		{`!rev`, []string{"from-tree"}},
+		{`path contains "tree"`, []string{"from-tree"}},
+		// `&&` binds more tightly than `||`.
+		{`slug == "from-tree" || fromYear < 1980 && decades == 1970`, []string{"orlando-the-asexual-manifesto", "from-tree"}},
+		{`(slug == "from-tree" || fromYear < 1980) && decades == 2010`, []string{"from-tree"}},
+		{`!!true`, []string{"orlando-the-asexual-manifesto", "a-zine", "from-tree"}},
+		{`false`, []string{}},
+		{`missing == "value"`, []string{}},
+		{`missing != "value"`, []string{"orlando-the-asexual-manifesto", "a-zine", "from-tree"}},
+		{`title == "quote \" inside"`, []string{}},
+		{`fromYear > -1`, []string{"orlando-the-asexual-manifesto", "a-zine", "from-tree"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			if got := matchingSlugs(t, test.expression); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("matches = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`slug ==`,
+		`== "a-zine"`,
+		`slug == "unterminated`,
+		`slug = "a-zine"`,
+		`slug == "a" &&`,
+		`(slug == "a"`,
+		`slug == "a")`,
+		`slug == "a" "b"`,
+		`fromYear > 1.2.3`,
+		`slug ~ "a"`,
+		`slug == "bad \q escape"`,
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := Parse(expression); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("Parse(%q) = %v, want %v", expression, err, ErrInvalidFilter)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenField
+	tokenString
+	tokenNumber
+	tokenBool
+	tokenOperator
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+// operators are the comparison operators, longest first so that `<=` isn't
+// lexed as `<`.
+var operators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+const operatorContains = "contains"
+
+func isFieldStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isFieldPart(r rune) bool {
+	return isFieldStart(r) || unicode.IsDigit(r) || r == '.'
+}
+
+func lex(expression string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(expression)
+	pos := 0
+
+	for pos < len(runes) {
+		r := runes[pos]
+		rest := string(runes[pos:])
+
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+		case strings.HasPrefix(rest, "&&"):
+			tokens = append(tokens, token{kind: tokenAnd, text: "&&", pos: pos})
+			pos += 2
+		case strings.HasPrefix(rest, "||"):
+			tokens = append(tokens, token{kind: tokenOr, text: "||", pos: pos})
+			pos += 2
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: pos})
+			pos++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: pos})
+			pos++
+		case r == '"':
+			end := pos + 1
+
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidFilter, pos)
+			}
+
+			text := string(runes[pos : end+1])
+
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid string at position %d", ErrInvalidFilter, pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: text, value: value, pos: pos})
+			pos = end + 1
+		case unicode.IsDigit(r) || (r == '-' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			end := pos + 1
+
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+
+			text := string(runes[pos:end])
+
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid number at position %d", ErrInvalidFilter, pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: pos})
+			pos = end
+		case isFieldStart(r):
+			end := pos + 1
+
+			for end < len(runes) && isFieldPart(runes[end]) {
+				end++
+			}
+
+			text := string(runes[pos:end])
+
+			switch text {
+			case operatorContains:
+				tokens = append(tokens, token{kind: tokenOperator, text: text, pos: pos})
+			case "true", "false":
+				tokens = append(tokens, token{kind: tokenBool, text: text, value: text == "true", pos: pos})
+			default:
+				tokens = append(tokens, token{kind: tokenField, text: text, pos: pos})
+			}
+
+			pos = end
+		default:
+			matched := false
+
+			for _, operator := range operators {
+				if strings.HasPrefix(rest, operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, pos: pos})
+					pos += len(operator)
+					matched = true
+
+					break
+				}
+			}
+
+			if !matched {
+				if r == '!' {
+					tokens = append(tokens, token{kind: tokenNot, text: "!", pos: pos})
+					pos++
+
+					continue
+				}
+
+				return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidFilter, r, pos)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// Filter is a parsed filter expression, like:
+//
+//	identities contains "asexual" && fromYear < 1980
+type Filter struct {
+	root node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of filter", ErrInvalidFilter)
+	}
+
+	return fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidFilter, tok.text, tok.pos)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch p.peek().kind {
+	case tokenNot:
+		p.next()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{operand: operand}, nil
+	case tokenOpen:
+		p.next()
+
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != tokenClose {
+			return nil, unexpected(tok)
+		}
+
+		return expression, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenOperator {
+		return left, nil
+	}
+
+	operator := p.next().text
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return comparisonNode{left: left, right: right, operator: operator}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	switch tok := p.next(); tok.kind {
+	case tokenField:
+		return fieldNode{path: strings.Split(tok.text, ".")}, nil
+	case tokenString, tokenNumber, tokenBool:
+		return literalNode{value: tok.value}, nil
+	default:
+		return nil, unexpected(tok)
+	}
+}
+
+// Parse parses a filter expression. Fields are compared with `==`, `!=`, `<`,
+// `<=`, `>`, `>=`, and `contains`, and comparisons are combined with `&&`,
+// `||`, `!`, and parentheses.
+func Parse(expression string) (*Filter, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, pos: 0}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.next(); tok.kind != tokenEOF {
+		return nil, unexpected(tok)
+	}
+
+	return &Filter{root: root}, nil
+}
+
+// Match returns whether `artifact` matches the filter.
+func (f *Filter) Match(artifact parse.Artifact) bool {
+	return truthy(f.root.eval(artifact))
+}
+
+// Apply returns the artifacts which match the filter. It doesn't modify
+// `artifacts`.
+func (f *Filter) Apply(artifacts []parse.Artifact) []parse.Artifact {
+	matches := make([]parse.Artifact, 0, len(artifacts))
+
+	for _, artifact := range artifacts {
+		if f.Match(artifact) {
+			matches = append(matches, artifact)
+		}
+	}
+
+	return matches
+}
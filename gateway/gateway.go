@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multibase"
+)
+
+var ErrInvalidCid = errors.New("this is not a valid CID")
+
+// CidV1 returns the CIDv1 of the CID `id` in base32, which is the form IPFS
+// subdomain gateways and browsers expect.
+func CidV1(id string) (string, error) {
+	parsedCid, err := cid.Decode(id)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidCid, id)
+	}
+
+	return cid.NewCidV1(parsedCid.Type(), parsedCid.Hash()).StringOfBase(multibase.Base32)
+}
+
+// URL returns the URL of the file with the CID `id` on the gateway at `base`.
+// If `filename` is not empty, the gateway is asked to serve the file with that
+// filename.
+func URL(base, id, filename string) (string, error) {
+	cidV1, err := CidV1(id)
+	if err != nil {
+		return "", err
+	}
+
+	fileURL := fmt.Sprintf("%s/ipfs/%s", strings.TrimSuffix(base, "/"), cidV1)
+
+	if filename != "" {
+		fileURL = fmt.Sprintf("%s?filename=%s", fileURL, url.QueryEscape(filename))
+	}
+
+	return fileURL, nil
+}
+
+// IpfsURL returns the native `ipfs://` URL of the file with the CID `id`.
+func IpfsURL(id string) (string, error) {
+	cidV1, err := CidV1(id)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ipfs://%s", cidV1), nil
+}
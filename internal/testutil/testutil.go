@@ -0,0 +1,71 @@
+// Package testutil builds artifacts for tests.
+package testutil
+
+import (
+	"time"
+
+	"github.com/acearchive/artifact-action/markdown"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// Date returns midnight UTC on `day` of January 2022, so tests can order the
+// versions of an artifact by day.
+func Date(day int) time.Time {
+	return time.Date(2022, time.January, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Artifact returns the version of the artifact `slug` committed at `date`.
+// Its entry is a valid artifact file of the current version, and each field in
+// `fields` replaces the default value of that field.
+func Artifact(slug string, date time.Time, fields parse.GenericEntry) parse.Artifact {
+	entry := parse.GenericEntry{
+		string(parse.FieldVersion):     parse.CurrentArtifactVersion,
+		string(parse.FieldTitle):       "The " + slug,
+		string(parse.FieldDescription): "A description of " + slug,
+		string(parse.FieldFiles):       []interface{}{},
+		string(parse.FieldLinks):       []interface{}{},
+		string(parse.FieldPeople):      []interface{}{},
+		string(parse.FieldIdentities):  []interface{}{},
+		string(parse.FieldFromYear):    2000,
+		string(parse.FieldDecades):     []interface{}{2000},
+		string(parse.FieldAliases):     []interface{}{},
+	}
+
+	for field, value := range fields {
+		entry[field] = value
+	}
+
+	title := entry.String(parse.FieldTitle)
+
+	return parse.Artifact{
+		Path:       "artifacts/" + slug + parse.ArtifactFileExtension,
+		Slug:       slug,
+		TitlePlain: markdown.Plain(title),
+		TitleHTML:  markdown.HTML(title),
+		SortKey:    markdown.SortKey(title),
+		Commit: &parse.ArtifactCommit{
+			Rev:  slug + "-" + date.Format("2006-01-02"),
+			Date: date,
+		},
+		Entry: entry,
+	}
+}
+
+// File returns a file in an artifact entry, which is named after its
+// filename. Empty fields are left out.
+func File(filename, fileCid, mediaType string) map[string]interface{} {
+	file := make(map[string]interface{})
+
+	for field, value := range map[parse.EntryField]string{
+		parse.FieldFileName:      filename,
+		parse.FieldFileFilename:  filename,
+		parse.FieldFileCid:       fileCid,
+		parse.FieldFileMediaType: mediaType,
+	} {
+		if value != "" {
+			file[string(field)] = value
+		}
+	}
+
+	return file
+}
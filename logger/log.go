@@ -1,73 +1,254 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/acearchive/artifact-action/cfg"
+	"github.com/ipfs/go-cid"
 )
 
-func Printf(format string, a ...interface{}) {
-	if cfg.Action() || cfg.Output() == cfg.OutputSummary {
-		fmt.Printf(format, a...) //nolint:forbidigo
+// Level is the severity of a log event. Events below the configured level are
+// discarded.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return string(cfg.LogLevelDebug)
+	case LevelInfo:
+		return string(cfg.LogLevelInfo)
+	case LevelWarn:
+		return string(cfg.LogLevelWarn)
+	default:
+		return string(cfg.LogLevelError)
 	}
 }
 
-func Println(a ...interface{}) {
-	if cfg.Action() || cfg.Output() == cfg.OutputSummary {
-		fmt.Println(a...) //nolint:forbidigo
+func levelFromConfig() Level {
+	switch cfg.LogLevel() {
+	case cfg.LogLevelDebug:
+		return LevelDebug
+	case cfg.LogLevelWarn:
+		return LevelWarn
+	case cfg.LogLevelError:
+		return LevelError
+	default:
+		return LevelInfo
 	}
 }
 
-func LogError(err error) {
-	if cfg.Action() {
-		fmt.Printf("::error::%s\n", err.Error()) //nolint:forbidigo
-	} else {
-		if _, err := fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error()); err != nil {
-			panic(err)
+// Field is a piece of structured data attached to a log event. Fields are
+// only rendered when logging in the JSON format.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Slug attaches the slug of the artifact the event is about.
+func Slug(slug string) Field {
+	return Field{Key: "slug", Value: slug}
+}
+
+// Cid attaches the CID of the content the event is about.
+func Cid(id cid.Cid) Field {
+	return Field{Key: "cid", Value: id.String()}
+}
+
+// Phase attaches the phase of the run the event is a part of, like `history`
+// or `pin`.
+func Phase(phase string) Field {
+	return Field{Key: "phase", Value: phase}
+}
+
+// Duration attaches how long the thing the event is about took. It's
+// rendered in seconds.
+func Duration(duration time.Duration) Field {
+	return Field{Key: "duration", Value: duration.Seconds()}
+}
+
+// writeJSON writes a log event to stderr as a single line of JSON.
+func writeJSON(level Level, msg string, fields []Field) {
+	var line bytes.Buffer
+
+	writeField := func(key string, value interface{}) {
+		marshalledValue, err := json.Marshal(value)
+		if err != nil {
+			marshalledValue, _ = json.Marshal(fmt.Sprint(value))
+		}
+
+		marshalledKey, _ := json.Marshal(key)
+
+		if line.Len() > 1 {
+			line.WriteByte(',')
 		}
+
+		line.Write(marshalledKey)
+		line.WriteByte(':')
+		line.Write(marshalledValue)
+	}
+
+	line.WriteByte('{')
+	writeField("time", time.Now().UTC().Format(time.RFC3339Nano))
+	writeField("level", level.String())
+	writeField("msg", msg)
+
+	for _, field := range fields {
+		writeField(field.Key, field.Value)
+	}
+
+	line.WriteString("}\n")
+
+	if _, err := os.Stderr.Write(line.Bytes()); err != nil {
+		panic(err)
 	}
 }
 
-func LogErrorGroup(name string, errList []error) {
-	if cfg.Action() {
-		fmt.Printf("::group::%s\n", name) //nolint:forbidigo
+// writeText writes a log event to stderr in a human-readable format.
+func writeText(format string, a ...interface{}) {
+	if _, err := fmt.Fprintf(os.Stderr, format, a...); err != nil {
+		panic(err)
+	}
+}
 
-		for _, err := range errList {
-			fmt.Println(err.Error()) //nolint:forbidigo
+func enabled(level Level) bool {
+	return level >= levelFromConfig()
+}
+
+func isJSON() bool {
+	return cfg.LogFormat() == cfg.LogFormatJSON
+}
+
+func LogDebug(msg string, fields ...Field) {
+	switch {
+	case isJSON():
+		if enabled(LevelDebug) {
+			writeJSON(LevelDebug, msg, fields)
 		}
+	case cfg.Action():
+		// The runner hides these unless step debug logging is enabled, so
+		// there's no need to filter them ourselves.
+		writeText("::debug::%s\n", msg)
+	case enabled(LevelDebug):
+		writeText("Debug: %s\n", msg)
+	}
+}
 
-		fmt.Println("::endgroup::") //nolint:forbidigo
+// LogInfo logs progress and summary statistics.
+func LogInfo(msg string, fields ...Field) {
+	if !enabled(LevelInfo) {
+		return
+	}
+
+	if isJSON() {
+		writeJSON(LevelInfo, msg, fields)
 	} else {
-		if _, err := fmt.Fprintln(os.Stderr, name); err != nil {
-			panic(err)
-		}
+		writeText("%s\n", msg)
+	}
+}
+
+// LogNotice logs something at the info level which should stand out, like a
+// notice annotation when running as a GitHub Action.
+func LogNotice(msg string, fields ...Field) {
+	if !enabled(LevelInfo) {
+		return
+	}
+
+	switch {
+	case isJSON():
+		writeJSON(LevelInfo, msg, fields)
+	case cfg.Action():
+		writeText("::notice::%s\n", msg)
+	default:
+		writeText("\n%s\n\n", msg)
+	}
+}
+
+func LogWarning(msg string, fields ...Field) {
+	if !enabled(LevelWarn) {
+		return
+	}
+
+	switch {
+	case isJSON():
+		writeJSON(LevelWarn, msg, fields)
+	case cfg.Action():
+		writeText("::warning::%s\n", msg)
+	default:
+		writeText("Warning: %s\n", msg)
+	}
+}
+
+func LogError(err error, fields ...Field) {
+	switch {
+	case isJSON():
+		writeJSON(LevelError, err.Error(), fields)
+	case cfg.Action():
+		writeText("::error::%s\n", err.Error())
+	default:
+		writeText("Error: %s\n", err.Error())
+	}
+}
 
+func LogErrorGroup(name string, errList []error) {
+	if isJSON() {
 		for _, err := range errList {
-			if _, err := fmt.Fprintln(os.Stderr, err.Error()); err != nil {
-				panic(err)
-			}
+			writeJSON(LevelError, strings.TrimSpace(err.Error()), []Field{{Key: "group", Value: name}})
 		}
+
+		return
+	}
+
+	lines := make([]string, len(errList))
+
+	for i, err := range errList {
+		lines[i] = err.Error()
 	}
+
+	logGroup(name, lines)
 }
 
-func LogNotice(msg string) {
-	if cfg.Action() {
-		fmt.Printf("::notice::%s\n", msg) //nolint:forbidigo
-	} else {
-		if _, err := fmt.Fprintf(os.Stderr, "\n%s\n\n", msg); err != nil {
-			panic(err)
+// LogGroup logs a collapsible group of lines at the info level.
+func LogGroup(name string, lines []string) {
+	if !enabled(LevelInfo) {
+		return
+	}
+
+	if isJSON() {
+		for _, line := range lines {
+			writeJSON(LevelInfo, line, []Field{{Key: "group", Value: name}})
 		}
+
+		return
 	}
+
+	logGroup(name, lines)
 }
 
-func LogWarning(msg string) {
+func logGroup(name string, lines []string) {
 	if cfg.Action() {
-		fmt.Printf("::warning::%s\n", msg) //nolint:forbidigo
+		writeText("::group::%s\n", name)
 	} else {
-		if _, err := fmt.Fprintf(os.Stderr, "Warning: %s\n", msg); err != nil {
-			panic(err)
-		}
+		writeText("%s\n", name)
+	}
+
+	for _, line := range lines {
+		writeText("%s\n", line)
+	}
+
+	if cfg.Action() {
+		writeText("::endgroup::\n")
 	}
 }
 
@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Artifact titles and descriptions may contain inline Markdown, most often
+// emphasis for the titles of works, like `*The Asexual Manifesto*`. This
+// package converts it for formats which don't support Markdown.
+
+// emphasisPattern matches one kind of emphasis, which is either strong
+// emphasis, regular emphasis, or both.
+type emphasisPattern struct {
+	pattern  *regexp.Regexp
+	strong   bool
+	emphasis bool
+}
+
+var (
+	escapePattern = regexp.MustCompile(`\\([\\` + "`" + `*_{}\[\]()#+\-.!])`)
+	codePattern   = regexp.MustCompile("`([^`]+)`")
+
+	// RE2 doesn't support backreferences, so there's a pattern for each
+	// delimiter.
+	emphasisPatterns = []emphasisPattern{
+		{pattern: regexp.MustCompile(`\*\*\*(\S(?:.*?\S)??)\*\*\*`), strong: true, emphasis: true},
+		{pattern: regexp.MustCompile(`(^|\W)___(\S(?:.*?\S)??)___($|\W)`), strong: true, emphasis: true},
+		{pattern: regexp.MustCompile(`\*\*(\S(?:.*?\S)??)\*\*`), strong: true, emphasis: false},
+		{pattern: regexp.MustCompile(`(^|\W)__(\S(?:.*?\S)??)__($|\W)`), strong: true, emphasis: false},
+		{pattern: regexp.MustCompile(`\*(\S(?:.*?\S)??)\*`), strong: false, emphasis: true},
+		{pattern: regexp.MustCompile(`(^|\W)_(\S(?:.*?\S)??)_($|\W)`), strong: false, emphasis: true},
+	}
+)
+
+// tags are the strings which the contents of each kind of inline Markdown are
+// wrapped in.
+type tags struct {
+	code     [2]string
+	strong   [2]string
+	emphasis [2]string
+}
+
+var (
+	plainTags = tags{code: [2]string{"", ""}, strong: [2]string{"", ""}, emphasis: [2]string{"", ""}}
+	htmlTags  = tags{
+		code:     [2]string{"<code>", "</code>"},
+		strong:   [2]string{"<strong>", "</strong>"},
+		emphasis: [2]string{"<em>", "</em>"},
+	}
+)
+
+// wrapper returns the strings which the contents of emphasis matching `e` are
+// wrapped in.
+func (e emphasisPattern) wrapper(wrap tags) [2]string {
+	switch {
+	case e.strong && e.emphasis:
+		return [2]string{wrap.emphasis[0] + wrap.strong[0], wrap.strong[1] + wrap.emphasis[1]}
+	case e.strong:
+		return wrap.strong
+	default:
+		return wrap.emphasis
+	}
+}
+
+// escapedRune returns the placeholder for a backslash-escaped character,
+// which is in a Unicode private use area so that it can't be mistaken for
+// Markdown.
+func escapedRune(r rune) string {
+	return string(r + 0xF0000)
+}
+
+// literal returns `text` with every ASCII character replaced with its
+// placeholder, so that it's not mistaken for Markdown.
+func literal(text string) string {
+	var builder strings.Builder
+
+	for _, r := range text {
+		if r < 0x80 {
+			builder.WriteString(escapedRune(r))
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+func unescapeRunes(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 0xF0000 && r < 0xF0080 {
+			return r - 0xF0000
+		}
+
+		return r
+	}, text)
+}
+
+// convert returns `text` with the contents of inline Markdown emphasis and
+// code spans wrapped in `wrap` instead of their delimiters.
+func convert(text string, wrap tags) string {
+	text = escapePattern.ReplaceAllStringFunc(text, func(escaped string) string {
+		return escapedRune(rune(escaped[1]))
+	})
+
+	// The contents of code spans are literal.
+	text = codePattern.ReplaceAllStringFunc(text, func(code string) string {
+		return literal(wrap.code[0] + code[1:len(code)-1] + wrap.code[1])
+	})
+
+	// Adjacent matches of the same pattern share the character between
+	// them, so we have to replace until there's nothing left.
+	for _, emphasis := range emphasisPatterns {
+		wrapper := emphasis.wrapper(wrap)
+
+		template := "${1}" + literal(wrapper[0]) + "${2}" + literal(wrapper[1]) + "${3}"
+		if emphasis.pattern.NumSubexp() == 1 {
+			template = literal(wrapper[0]) + "${1}" + literal(wrapper[1])
+		}
+
+		for replaced := emphasis.pattern.ReplaceAllString(text, template); replaced != text; {
+			text = replaced
+			replaced = emphasis.pattern.ReplaceAllString(text, template)
+		}
+	}
+
+	return strings.TrimSpace(unescapeRunes(text))
+}
+
+// Plain returns `text` with inline Markdown emphasis and code spans removed,
+// leaving only their contents.
+func Plain(text string) string {
+	return convert(text, plainTags)
+}
+
+// HTML returns `text` as an HTML fragment, with inline Markdown emphasis and
+// code spans converted to `em`, `strong`, and `code` elements. Everything
+// else is escaped.
+func HTML(text string) string {
+	return convert(html.EscapeString(text), htmlTags)
+}
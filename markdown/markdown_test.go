@@ -0,0 +1,103 @@
+package markdown
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantPlain string
+		wantHTML  string
+	}{
+		{
+			name:      "emphasis",
+			text:      "*The Asexual Manifesto*",
+			wantPlain: "The Asexual Manifesto",
+			wantHTML:  "<em>The Asexual Manifesto</em>",
+		},
+		{
+			name:      "strong and emphasis",
+			text:      "**Bold** and _italic_",
+			wantPlain: "Bold and italic",
+			wantHTML:  "<strong>Bold</strong> and <em>italic</em>",
+		},
+		{
+			name:      "strong emphasis",
+			text:      "***Both*** and ___both___",
+			wantPlain: "Both and both",
+			wantHTML:  "<em><strong>Both</strong></em> and <em><strong>both</strong></em>",
+		},
+		{
+			name:      "underscores inside words",
+			text:      "snake_case_name and dunder__name__",
+			wantPlain: "snake_case_name and dunder__name__",
+			wantHTML:  "snake_case_name and dunder__name__",
+		},
+		{
+			name:      "underscores around words",
+			text:      "A __strong__ and _emphasized_ word",
+			wantPlain: "A strong and emphasized word",
+			wantHTML:  "A <strong>strong</strong> and <em>emphasized</em> word",
+		},
+		{
+			name:      "asterisks inside words",
+			text:      "un*frigging*believable",
+			wantPlain: "unfriggingbelievable",
+			wantHTML:  "un<em>frigging</em>believable",
+		},
+		{
+			name:      "escaped delimiters",
+			text:      `\*Not emphasis\* and \_not\_ either`,
+			wantPlain: "*Not emphasis* and _not_ either",
+			wantHTML:  "*Not emphasis* and _not_ either",
+		},
+		{
+			name:      "escaped backslash",
+			text:      `C:\\ *drive*`,
+			wantPlain: `C:\ drive`,
+			wantHTML:  `C:\ <em>drive</em>`,
+		},
+		{
+			name:      "code spans are literal",
+			text:      "The `*ace*` flag",
+			wantPlain: "The *ace* flag",
+			wantHTML:  "The <code>*ace*</code> flag",
+		},
+		{
+			name:      "delimiters surrounded by spaces",
+			text:      "5 * 3 * 2",
+			wantPlain: "5 * 3 * 2",
+			wantHTML:  "5 * 3 * 2",
+		},
+		{
+			name:      "HTML is escaped",
+			text:      `*<Aces> & "Aros"*`,
+			wantPlain: `<Aces> & "Aros"`,
+			wantHTML:  "<em>&lt;Aces&gt; &amp; &#34;Aros&#34;</em>",
+		},
+		{
+			name:      "HTML in code spans is escaped",
+			text:      "`a < b`",
+			wantPlain: "a < b",
+			wantHTML:  "<code>a &lt; b</code>",
+		},
+		{
+			name:      "surrounding space",
+			text:      "  Plain title \n",
+			wantPlain: "Plain title",
+			wantHTML:  "Plain title",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Plain(test.text); got != test.wantPlain {
+				t.Errorf("Plain(%q) = %q, want %q", test.text, got, test.wantPlain)
+			}
+
+			if got := HTML(test.text); got != test.wantHTML {
+				t.Errorf("HTML(%q) = %q, want %q", test.text, got, test.wantHTML)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package markdown
+
+import (
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// articles are the leading words which are ignored when sorting. Titles in
+// the archive are in English.
+var articles = map[string]struct{}{
+	"a":   {},
+	"an":  {},
+	"the": {},
+}
+
+// SortKey returns a key for sorting `text` which ignores inline Markdown,
+// punctuation, and a leading article, and which collates the rest like an
+// English dictionary, ignoring case and diacritics. The key is the English
+// collation key of the text, hex-encoded so that comparing the keys of two
+// titles as strings, byte by byte, sorts them like a library catalog would
+// in any language.
+func SortKey(text string) string {
+	// Dashes separate words, but other punctuation and symbols, like
+	// apostrophes, are removed.
+	words := strings.Fields(strings.Map(func(r rune) rune {
+		switch {
+		case unicode.Is(unicode.Pd, r) || unicode.IsSpace(r):
+			return ' '
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			return -1
+		default:
+			return r
+		}
+	}, Plain(text)))
+
+	if len(words) > 1 {
+		if _, isArticle := articles[cases.Fold().String(words[0])]; isArticle {
+			words = words[1:]
+		}
+	}
+
+	// Collators aren't safe for concurrent use, so they can't be shared
+	// between calls.
+	collator := collate.New(language.English, collate.Loose)
+
+	var buf collate.Buffer
+
+	return hex.EncodeToString(collator.KeyFromString(&buf, strings.Join(words, " ")))
+}
@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSortKeyIgnores(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{name: "Markdown", a: "*The Asexual Manifesto*", b: "The Asexual Manifesto"},
+		{name: "case", a: "ASEXUAL MANIFESTO", b: "asexual manifesto"},
+		{name: "diacritics", a: "Élan", b: "elan"},
+		{name: "punctuation", a: "Aces' Guide: Part 1", b: "Aces Guide Part 1"},
+		{name: "dashes", a: "Ace-spectrum", b: "ace spectrum"},
+		{name: "leading the", a: "The Asexual Manifesto", b: "Asexual Manifesto"},
+		{name: "leading a", a: "A Zine About Aces", b: "Zine About Aces"},
+		{name: "leading an", a: "An Ace Anthology", b: "Ace Anthology"},
+		{name: "leading article with emphasis", a: "_The_ Manifesto", b: "Manifesto"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if a, b := SortKey(test.a), SortKey(test.b); a != b {
+				t.Errorf("SortKey(%q) = %q, SortKey(%q) = %q, want them equal", test.a, a, test.b, b)
+			}
+		})
+	}
+}
+
+func TestSortKeyKeepsArticles(t *testing.T) {
+	// A title which is only an article, or which has one in the middle,
+	// keeps it.
+	if SortKey("The") == SortKey("") {
+		t.Error(`SortKey("The") is empty`)
+	}
+
+	if SortKey("Manifesto, The Second") == SortKey("Manifesto Second") {
+		t.Error("SortKey() removed an article which isn't at the start")
+	}
+}
+
+func TestSortKeyOrder(t *testing.T) {
+	want := []string{
+		"Ace",
+		"*The Ace Community*",
+		"Élan",
+		"Eland",
+		"an Interview",
+		"The Zine",
+	}
+
+	titles := []string{want[5], want[2], want[0], want[4], want[3], want[1]}
+
+	sort.Slice(titles, func(i, j int) bool {
+		return SortKey(titles[i]) < SortKey(titles[j])
+	})
+
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("titles sorted by SortKey() = %q, want %q", titles, want)
+	}
+}
@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+const (
+	oaiDCNamespace      = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	dcNamespace         = "http://purl.org/dc/elements/1.1/"
+	xsiNamespace        = "http://www.w3.org/2001/XMLSchema-instance"
+	oaiDCSchemaLocation = "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	dublinCoreIndent    = "  "
+)
+
+// DublinCore is the simple Dublin Core description of an artifact, in the
+// `oai_dc` format used by OAI-PMH.
+type DublinCore struct {
+	XMLName        xml.Name `xml:"oai_dc:dc"`
+	OaiDC          string   `xml:"xmlns:oai_dc,attr"`
+	DC             string   `xml:"xmlns:dc,attr"`
+	Xsi            string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          string   `xml:"dc:title"`
+	Creator        []string `xml:"dc:creator"`
+	Subject        []string `xml:"dc:subject"`
+	Description    string   `xml:"dc:description"`
+	Date           string   `xml:"dc:date"`
+	Format         []string `xml:"dc:format"`
+	Identifier     []string `xml:"dc:identifier"`
+	Relation       []string `xml:"dc:relation"`
+	Coverage       []string `xml:"dc:coverage"`
+	Publisher      string   `xml:"dc:publisher"`
+}
+
+type dublinCoreRecords struct {
+	XMLName xml.Name     `xml:"records"`
+	Records []DublinCore `xml:"oai_dc:dc"`
+}
+
+// NewDublinCore returns the Dublin Core description of the artifact in
+// `record`.
+func NewDublinCore(record Record, opts Options) DublinCore {
+	dc := DublinCore{
+		OaiDC:          oaiDCNamespace,
+		DC:             dcNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: oaiDCSchemaLocation,
+		Title:          record.Title,
+		Creator:        record.Entry.People,
+		Subject:        record.Entry.Identities,
+		Description:    record.Description(),
+		Date:           record.Years(),
+		Format:         nil,
+		Identifier:     nil,
+		Relation:       nil,
+		Coverage:       nil,
+		Publisher:      ArchiveName,
+	}
+
+	if record.URL != "" {
+		dc.Identifier = append(dc.Identifier, record.URL)
+	}
+
+	seenFormats := make(map[string]struct{})
+
+	for _, file := range record.Entry.Files {
+		if url := fileURL(opts, file); url != "" {
+			dc.Identifier = append(dc.Identifier, url)
+		}
+
+		if file.MediaType == nil {
+			continue
+		}
+
+		if _, seen := seenFormats[*file.MediaType]; !seen {
+			seenFormats[*file.MediaType] = struct{}{}
+			dc.Format = append(dc.Format, *file.MediaType)
+		}
+	}
+
+	for _, link := range record.Entry.Links {
+		dc.Relation = append(dc.Relation, link.URL)
+	}
+
+	for _, decade := range record.Entry.Decades {
+		dc.Coverage = append(dc.Coverage, fmt.Sprintf("%ds", decade))
+	}
+
+	return dc
+}
+
+// WriteDublinCore writes the Dublin Core description of each artifact in
+// `records` to `w` as XML. A single artifact is written as an `oai_dc:dc`
+// element, and multiple artifacts are wrapped in a `records` element.
+func WriteDublinCore(w io.Writer, records []Record, opts Options) error {
+	var document interface{}
+
+	if len(records) == 1 {
+		document = NewDublinCore(records[0], opts)
+	} else {
+		wrapper := dublinCoreRecords{Records: make([]DublinCore, len(records))}
+
+		for i, record := range records {
+			wrapper.Records[i] = NewDublinCore(record, opts)
+		}
+
+		document = wrapper
+	}
+
+	marshalledDocument, err := xml.MarshalIndent(document, "", dublinCoreIndent)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, marshalledDocument)
+
+	return err
+}
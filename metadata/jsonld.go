@@ -0,0 +1,111 @@
+package metadata
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const schemaOrgContext = "https://schema.org"
+
+type jsonldThing struct {
+	Type string `json:"@type,omitempty"`
+	Name string `json:"name"`
+}
+
+type jsonldMediaObject struct {
+	Type           string `json:"@type"`
+	Name           string `json:"name"`
+	EncodingFormat string `json:"encodingFormat,omitempty"`
+	ContentURL     string `json:"contentUrl,omitempty"`
+	Identifier     string `json:"identifier"`
+}
+
+// JSONLDArtifact is the schema.org description of an artifact.
+type JSONLDArtifact struct {
+	Context          string              `json:"@context,omitempty"`
+	ID               string              `json:"@id,omitempty"`
+	Type             []string            `json:"@type"`
+	Identifier       string              `json:"identifier"`
+	Name             string              `json:"name"`
+	Description      string              `json:"description"`
+	URL              string              `json:"url,omitempty"`
+	Creator          []jsonldThing       `json:"creator,omitempty"`
+	TemporalCoverage string              `json:"temporalCoverage"`
+	Keywords         []string            `json:"keywords,omitempty"`
+	SameAs           []string            `json:"sameAs,omitempty"`
+	HoldingArchive   jsonldThing         `json:"holdingArchive"`
+	Encoding         []jsonldMediaObject `json:"encoding,omitempty"`
+}
+
+type jsonldGraph struct {
+	Context string           `json:"@context"`
+	Graph   []JSONLDArtifact `json:"@graph"`
+}
+
+// NewJSONLD returns the schema.org description of the artifact in `record`.
+func NewJSONLD(record Record, opts Options) JSONLDArtifact {
+	artifact := JSONLDArtifact{
+		Context:          "",
+		ID:               record.URL,
+		Type:             []string{"CreativeWork", "ArchiveComponent"},
+		Identifier:       record.Slug,
+		Name:             record.Title,
+		Description:      record.Description(),
+		URL:              record.URL,
+		Creator:          make([]jsonldThing, len(record.Entry.People)),
+		TemporalCoverage: record.Years(),
+		Keywords:         record.Entry.Identities,
+		SameAs:           make([]string, len(record.Entry.Links)),
+		HoldingArchive:   jsonldThing{Type: "ArchiveOrganization", Name: ArchiveName},
+		Encoding:         make([]jsonldMediaObject, len(record.Entry.Files)),
+	}
+
+	// People can be organizations, so we don't say that they're a `Person`.
+	for i, person := range record.Entry.People {
+		artifact.Creator[i] = jsonldThing{Type: "", Name: person}
+	}
+
+	for i, link := range record.Entry.Links {
+		artifact.SameAs[i] = link.URL
+	}
+
+	for i, file := range record.Entry.Files {
+		mediaType := ""
+		if file.MediaType != nil {
+			mediaType = *file.MediaType
+		}
+
+		artifact.Encoding[i] = jsonldMediaObject{
+			Type:           "MediaObject",
+			Name:           file.Name,
+			EncodingFormat: mediaType,
+			ContentURL:     fileURL(opts, file),
+			Identifier:     file.Cid,
+		}
+	}
+
+	return artifact
+}
+
+// WriteJSONLD writes the schema.org description of each artifact in `records`
+// to `w` as JSON-LD. A single artifact is written as a single object, and
+// multiple artifacts are written as a graph.
+func WriteJSONLD(w io.Writer, records []Record, opts Options) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if len(records) == 1 {
+		artifact := NewJSONLD(records[0], opts)
+		artifact.Context = schemaOrgContext
+
+		return encoder.Encode(artifact)
+	}
+
+	graph := jsonldGraph{Context: schemaOrgContext, Graph: make([]JSONLDArtifact, len(records))}
+
+	for i, record := range records {
+		graph.Graph[i] = NewJSONLD(record, opts)
+	}
+
+	return encoder.Encode(graph)
+}
@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acearchive/artifact-action/gateway"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/markdown"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// SchemaVersion is the version of the artifact file schema that the mappings
+// in this package are written for. When the schema changes, update the
+// mappings along with it and bump this to match `parse.CurrentArtifactVersion`.
+const SchemaVersion = 3
+
+// ArchiveName is the name of the archive which holds the artifacts.
+const ArchiveName = "Ace Archive"
+
+var ErrUnsupportedVersion = errors.New("there is no metadata mapping for this schema version")
+
+// Options configures the metadata of artifacts.
+type Options struct {
+	// SiteURL is the base URL of artifact pages, which the slug of each
+	// artifact is appended to. It may be empty.
+	SiteURL string
+
+	// Gateway is the base URL of the IPFS gateway to use in URLs for files.
+	Gateway string
+}
+
+// Record is an artifact along with its entry in the current schema.
+type Record struct {
+	Slug string
+
+	// Title is the title of the artifact without Markdown.
+	Title string
+
+	Entry parse.ArtifactEntry
+
+	// URL is the URL of the page for the artifact, or empty if there's no
+	// site URL.
+	URL string
+}
+
+// Description returns the description of the artifact without Markdown.
+func (r Record) Description() string {
+	return markdown.Plain(r.Entry.Description)
+}
+
+// Years returns the years of the artifact as an ISO 8601 year or interval of
+// years, like `1972` or `1972/1975`.
+func (r Record) Years() string {
+	if r.Entry.ToYear == nil || *r.Entry.ToYear == r.Entry.FromYear {
+		return fmt.Sprint(r.Entry.FromYear)
+	}
+
+	return fmt.Sprintf("%d/%d", r.Entry.FromYear, *r.Entry.ToYear)
+}
+
+// fileURL returns the gateway URL of a file, or an empty string if its CID is
+// invalid.
+func fileURL(opts Options, file parse.ArtifactEntryFile) string {
+	url, err := gateway.URL(opts.Gateway, file.Cid, file.Filename)
+	if err != nil {
+		return ""
+	}
+
+	return url
+}
+
+// NewRecord returns the metadata record of `artifact`, or
+// `ErrUnsupportedVersion` if it's from a different version of the schema.
+func NewRecord(artifact parse.Artifact, opts Options) (Record, error) {
+	if version, _ := artifact.Entry.Int(parse.FieldVersion); version != SchemaVersion {
+		return Record{}, fmt.Errorf("%w: %s is version %d", ErrUnsupportedVersion, artifact.Slug, version)
+	}
+
+	entry, err := artifact.Entry.Typed()
+	if err != nil {
+		return Record{}, fmt.Errorf("%s: %w", artifact.Slug, err)
+	}
+
+	record := Record{Slug: artifact.Slug, Title: artifact.TitlePlain, Entry: entry, URL: ""}
+
+	if opts.SiteURL != "" {
+		record.URL = fmt.Sprintf("%s/%s", strings.TrimSuffix(opts.SiteURL, "/"), artifact.Slug)
+	}
+
+	return record, nil
+}
+
+// NewRecords returns the metadata record of the most recent version of each
+// artifact in `artifacts`, sorted by slug. Artifacts from other versions of
+// the schema are skipped with a warning.
+func NewRecords(artifacts []parse.Artifact, opts Options) ([]Record, error) {
+	latest := parse.Latest(artifacts)
+	records := make([]Record, 0, len(latest))
+
+	for _, artifact := range latest {
+		record, err := NewRecord(artifact, opts)
+		if errors.Is(err, ErrUnsupportedVersion) {
+			logger.LogWarning(err.Error(), logger.Slug(artifact.Slug), logger.Phase("export"))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Slug < records[j].Slug
+	})
+
+	return records, nil
+}
@@ -0,0 +1,187 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const testCid = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+
+var testOptions = Options{SiteURL: "https://example.com/artifacts/", Gateway: "https://gateway.example.com"}
+
+func manifestoArtifact() parse.Artifact {
+	return testutil.Artifact("orlando-the-asexual-manifesto", testutil.Date(1), parse.GenericEntry{
+		string(parse.FieldTitle):       "*The Asexual Manifesto*",
+		string(parse.FieldDescription): "A paper by the _New York Radical Feminists_",
+		string(parse.FieldFiles): []interface{}{
+			testutil.File("scan.pdf", testCid, "application/pdf"),
+		},
+		string(parse.FieldLinks): []interface{}{
+			map[string]interface{}{"name": "Website", "url": "https://example.org/manifesto"},
+		},
+		string(parse.FieldPeople):     []interface{}{"Lisa Orlando"},
+		string(parse.FieldIdentities): []interface{}{"asexual"},
+		string(parse.FieldFromYear):   1972,
+		string(parse.FieldToYear):     1975,
+		string(parse.FieldDecades):    []interface{}{1970},
+	})
+}
+
+// The mappings in this package need to be updated whenever the schema
+// changes, so this fails as a reminder when it does.
+func TestSchemaVersion(t *testing.T) {
+	if SchemaVersion != parse.CurrentArtifactVersion {
+		t.Errorf(
+			"the metadata mapping is for schema version %d, but the current schema version is %d",
+			SchemaVersion,
+			parse.CurrentArtifactVersion,
+		)
+	}
+}
+
+func TestNewRecordsSkipsOtherVersions(t *testing.T) {
+	oldArtifact := testutil.Artifact("old", testutil.Date(1), parse.GenericEntry{
+		string(parse.FieldVersion): SchemaVersion - 1,
+	})
+
+	if _, err := NewRecord(oldArtifact, testOptions); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("NewRecord() = %v, want %v", err, ErrUnsupportedVersion)
+	}
+
+	records, err := NewRecords([]parse.Artifact{oldArtifact, manifestoArtifact()}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 || records[0].Slug != "orlando-the-asexual-manifesto" {
+		t.Errorf("NewRecords() = %+v, want only the current version", records)
+	}
+}
+
+func TestWriteJSONLD(t *testing.T) {
+	records, err := NewRecords([]parse.Artifact{manifestoArtifact()}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := WriteJSONLD(&buf, records, testOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@id":              "https://example.com/artifacts/orlando-the-asexual-manifesto",
+		"@type":            []interface{}{"CreativeWork", "ArchiveComponent"},
+		"identifier":       "orlando-the-asexual-manifesto",
+		"name":             "The Asexual Manifesto",
+		"description":      "A paper by the New York Radical Feminists",
+		"url":              "https://example.com/artifacts/orlando-the-asexual-manifesto",
+		"creator":          []interface{}{map[string]interface{}{"name": "Lisa Orlando"}},
+		"temporalCoverage": "1972/1975",
+		"keywords":         []interface{}{"asexual"},
+		"sameAs":           []interface{}{"https://example.org/manifesto"},
+		"holdingArchive":   map[string]interface{}{"@type": "ArchiveOrganization", "name": "Ace Archive"},
+		"encoding": []interface{}{
+			map[string]interface{}{
+				"@type":          "MediaObject",
+				"name":           "scan.pdf",
+				"encodingFormat": "application/pdf",
+				"contentUrl":     "https://gateway.example.com/ipfs/" + testCid + "?filename=scan.pdf",
+				"identifier":     testCid,
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteJSONLD() =\n%s", buf.String())
+	}
+}
+
+func TestWriteJSONLDGraph(t *testing.T) {
+	artifacts := []parse.Artifact{manifestoArtifact(), testutil.Artifact("zine", testutil.Date(1), nil)}
+
+	records, err := NewRecords(artifacts, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := WriteJSONLD(&buf, records, testOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	var got jsonldGraph
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Context != schemaOrgContext || len(got.Graph) != 2 {
+		t.Fatalf("WriteJSONLD() = %+v, want a graph of 2 artifacts", got)
+	}
+
+	for _, artifact := range got.Graph {
+		if artifact.Context != "" {
+			t.Errorf("%s has its own @context", artifact.Identifier)
+		}
+	}
+}
+
+func TestWriteDublinCore(t *testing.T) {
+	records, err := NewRecords([]parse.Artifact{manifestoArtifact()}, testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := WriteDublinCore(&buf, records, testOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Title      string   `xml:"title"`
+		Creator    []string `xml:"creator"`
+		Date       string   `xml:"date"`
+		Format     []string `xml:"format"`
+		Identifier []string `xml:"identifier"`
+		Coverage   []string `xml:"coverage"`
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Title != "The Asexual Manifesto" || got.Date != "1972/1975" {
+		t.Errorf("title = %q, date = %q", got.Title, got.Date)
+	}
+
+	wantIdentifiers := []string{
+		"https://example.com/artifacts/orlando-the-asexual-manifesto",
+		"https://gateway.example.com/ipfs/" + testCid + "?filename=scan.pdf",
+	}
+
+	if !reflect.DeepEqual(got.Identifier, wantIdentifiers) {
+		t.Errorf("identifiers = %v, want %v", got.Identifier, wantIdentifiers)
+	}
+
+	if !reflect.DeepEqual(got.Format, []string{"application/pdf"}) || !reflect.DeepEqual(got.Coverage, []string{"1970s"}) {
+		t.Errorf("formats = %v, coverage = %v", got.Format, got.Coverage)
+	}
+}
@@ -0,0 +1,494 @@
+package oai
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// DefaultPageSize is the default maximum number of records or identifiers in
+// a single response.
+const DefaultPageSize = 100
+
+// allowedArguments is the arguments each verb accepts, other than `verb`.
+var allowedArguments = map[string][]string{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier"},
+	"ListSets":            {"resumptionToken"},
+	"GetRecord":           {"identifier", "metadataPrefix"},
+	"ListIdentifiers":     {"metadataPrefix", "from", "until", "set", "resumptionToken"},
+	"ListRecords":         {"metadataPrefix", "from", "until", "set", "resumptionToken"},
+}
+
+// Handler serves an OAI-PMH repository of artifacts.
+type Handler struct {
+	repo *repository
+	opts Options
+}
+
+// NewHandler returns a handler which serves the artifacts in the history
+// `artifacts` over OAI-PMH.
+func NewHandler(artifacts []parse.Artifact, opts Options) *Handler {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+
+	return &Handler{repo: newRepository(artifacts, opts), opts: opts}
+}
+
+// protocolError is an error response as defined by the protocol.
+type protocolError struct {
+	code    string
+	message string
+}
+
+func (e protocolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+func newProtocolError(code, format string, args ...interface{}) *protocolError {
+	return &protocolError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// listArguments is the arguments of a list request, which are encoded in the
+// resumption token so that each page is consistent with the first.
+type listArguments struct {
+	from   *time.Time
+	until  *time.Time
+	set    string
+	offset int
+}
+
+func (h *Handler) identifier(slug string) string {
+	return fmt.Sprintf("oai:%s:%s", h.opts.Identifier, slug)
+}
+
+func (h *Handler) slugOf(identifier string) (string, bool) {
+	prefix := fmt.Sprintf("oai:%s:", h.opts.Identifier)
+	if !strings.HasPrefix(identifier, prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(identifier, prefix), true
+}
+
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.Path)
+}
+
+// parseDatestamp parses a `from` or `until` argument, which may be a day or a
+// time in UTC. An `until` day includes the whole day.
+func parseDatestamp(value string, isUntil bool) (*time.Time, string, error) {
+	if datestamp, err := time.Parse(datestampLayout, value); err == nil {
+		return &datestamp, datestampLayout, nil
+	}
+
+	datestamp, err := time.Parse(dayLayout, value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if isUntil {
+		datestamp = datestamp.Add(24*time.Hour - time.Second)
+	}
+
+	return &datestamp, dayLayout, nil
+}
+
+func encodeToken(args listArguments) string {
+	values := url.Values{}
+	values.Set("offset", strconv.Itoa(args.offset))
+
+	if args.from != nil {
+		values.Set("from", args.from.Format(datestampLayout))
+	}
+
+	if args.until != nil {
+		values.Set("until", args.until.Format(datestampLayout))
+	}
+
+	if args.set != "" {
+		values.Set("set", args.set)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(values.Encode()))
+}
+
+func decodeToken(token string) (listArguments, error) {
+	var args listArguments
+
+	rawToken, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return args, err
+	}
+
+	values, err := url.ParseQuery(string(rawToken))
+	if err != nil {
+		return args, err
+	}
+
+	if args.offset, err = strconv.Atoi(values.Get("offset")); err != nil || args.offset < 0 {
+		return args, fmt.Errorf("invalid offset")
+	}
+
+	for _, bound := range []struct {
+		name  string
+		value **time.Time
+	}{{"from", &args.from}, {"until", &args.until}} {
+		if values.Get(bound.name) == "" {
+			continue
+		}
+
+		datestamp, err := time.Parse(datestampLayout, values.Get(bound.name))
+		if err != nil {
+			return args, err
+		}
+
+		*bound.value = &datestamp
+	}
+
+	args.set = values.Get("set")
+
+	return args, nil
+}
+
+// parseListArguments parses the arguments of ListIdentifiers or ListRecords.
+func parseListArguments(args url.Values) (listArguments, *protocolError) {
+	if token := args.Get("resumptionToken"); token != "" {
+		if len(args) > 2 {
+			return listArguments{}, newProtocolError(errorBadArgument, "resumptionToken is an exclusive argument")
+		}
+
+		listArgs, err := decodeToken(token)
+		if err != nil {
+			return listArguments{}, newProtocolError(errorBadResumptionToken, "the resumption token is invalid")
+		}
+
+		return listArgs, nil
+	}
+
+	if err := checkMetadataPrefix(args); err != nil {
+		return listArguments{}, err
+	}
+
+	listArgs := listArguments{from: nil, until: nil, set: args.Get("set"), offset: 0}
+
+	var fromLayout, untilLayout string
+
+	if from := args.Get("from"); from != "" {
+		datestamp, layout, err := parseDatestamp(from, false)
+		if err != nil {
+			return listArguments{}, newProtocolError(errorBadArgument, "invalid from datestamp: %s", from)
+		}
+
+		listArgs.from, fromLayout = datestamp, layout
+	}
+
+	if until := args.Get("until"); until != "" {
+		datestamp, layout, err := parseDatestamp(until, true)
+		if err != nil {
+			return listArguments{}, newProtocolError(errorBadArgument, "invalid until datestamp: %s", until)
+		}
+
+		listArgs.until, untilLayout = datestamp, layout
+	}
+
+	if fromLayout != "" && untilLayout != "" && fromLayout != untilLayout {
+		return listArguments{}, newProtocolError(errorBadArgument, "from and until must have the same granularity")
+	}
+
+	if listArgs.from != nil && listArgs.until != nil && listArgs.from.After(*listArgs.until) {
+		return listArguments{}, newProtocolError(errorBadArgument, "from must not be after until")
+	}
+
+	return listArgs, nil
+}
+
+func checkMetadataPrefix(args url.Values) *protocolError {
+	switch prefix := args.Get("metadataPrefix"); prefix {
+	case "":
+		return newProtocolError(errorBadArgument, "metadataPrefix is required")
+	case metadataPrefix:
+		return nil
+	default:
+		return newProtocolError(errorCannotDisseminateFormat, "the only metadata format is %s", metadataPrefix)
+	}
+}
+
+func (h *Handler) header(rec record) header {
+	return header{
+		Identifier: h.identifier(rec.Slug),
+		Datestamp:  rec.Datestamp.Format(datestampLayout),
+		SetSpecs:   rec.SetSpecs,
+	}
+}
+
+func (h *Handler) recordElement(rec record) recordElement {
+	return recordElement{
+		Header:   h.header(rec),
+		Metadata: recordMetadata{DublinCore: rec.DublinCore},
+	}
+}
+
+// page returns the records which match `listArgs`, starting at its offset,
+// along with the resumption token for the next page.
+func (h *Handler) page(listArgs listArguments) ([]record, *resumptionToken, *protocolError) {
+	var matches []record
+
+	for _, rec := range h.repo.records {
+		if listArgs.from != nil && rec.Datestamp.Before(*listArgs.from) {
+			continue
+		}
+
+		if listArgs.until != nil && rec.Datestamp.After(*listArgs.until) {
+			continue
+		}
+
+		if listArgs.set != "" && !rec.inSet(listArgs.set) {
+			continue
+		}
+
+		matches = append(matches, rec)
+	}
+
+	if listArgs.offset > len(matches) {
+		return nil, nil, newProtocolError(errorBadResumptionToken, "the resumption token is out of date")
+	}
+
+	if len(matches) == 0 {
+		return nil, nil, newProtocolError(errorNoRecordsMatch, "no records match the arguments")
+	}
+
+	end := listArgs.offset + h.opts.PageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	var token *resumptionToken
+
+	// The last page of an incomplete list has an empty resumption token.
+	if end < len(matches) || listArgs.offset > 0 {
+		token = &resumptionToken{CompleteListSize: len(matches), Cursor: listArgs.offset, Token: ""}
+
+		if end < len(matches) {
+			nextArgs := listArgs
+			nextArgs.offset = end
+			token.Token = encodeToken(nextArgs)
+		}
+	}
+
+	return matches[listArgs.offset:end], token, nil
+}
+
+func (h *Handler) handleVerb(verb string, args url.Values, resp *response, base string) *protocolError {
+	switch verb {
+	case "Identify":
+		earliest := h.repo.earliest
+		if earliest.IsZero() {
+			earliest = time.Unix(0, 0).UTC()
+		}
+
+		resp.Identify = &identify{
+			RepositoryName:    h.opts.Name,
+			BaseURL:           base,
+			ProtocolVersion:   protocolVersion,
+			AdminEmail:        h.opts.AdminEmail,
+			EarliestDatestamp: earliest.Format(datestampLayout),
+			DeletedRecord:     "no",
+			Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+		}
+	case "ListMetadataFormats":
+		if identifier := args.Get("identifier"); identifier != "" {
+			if _, err := h.lookup(identifier); err != nil {
+				return err
+			}
+		}
+
+		resp.ListMetadataFormats = &listMetadataFormats{Formats: []metadataFormat{{
+			MetadataPrefix:    metadataPrefix,
+			Schema:            oaiDCSchema,
+			MetadataNamespace: oaiDCNamespace,
+		}}}
+	case "ListSets":
+		if args.Get("resumptionToken") != "" {
+			return newProtocolError(errorBadResumptionToken, "ListSets doesn't use resumption tokens")
+		}
+
+		sets := make([]setElement, len(h.repo.sets))
+
+		for i, s := range h.repo.sets {
+			sets[i] = setElement{SetSpec: s.Spec, SetName: s.Name}
+		}
+
+		resp.ListSets = &listSets{Sets: sets}
+	case "GetRecord":
+		if args.Get("identifier") == "" {
+			return newProtocolError(errorBadArgument, "identifier is required")
+		}
+
+		if err := checkMetadataPrefix(args); err != nil {
+			return err
+		}
+
+		rec, err := h.lookup(args.Get("identifier"))
+		if err != nil {
+			return err
+		}
+
+		resp.GetRecord = &getRecord{Record: h.recordElement(rec)}
+	case "ListIdentifiers", "ListRecords":
+		listArgs, err := parseListArguments(args)
+		if err != nil {
+			return err
+		}
+
+		records, token, err := h.page(listArgs)
+		if err != nil {
+			return err
+		}
+
+		if verb == "ListIdentifiers" {
+			resp.ListIdentifiers = &listIdentifiers{Headers: make([]header, len(records)), ResumptionToken: token}
+
+			for i, rec := range records {
+				resp.ListIdentifiers.Headers[i] = h.header(rec)
+			}
+		} else {
+			resp.ListRecords = &listRecords{Records: make([]recordElement, len(records)), ResumptionToken: token}
+
+			for i, rec := range records {
+				resp.ListRecords.Records[i] = h.recordElement(rec)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) lookup(identifier string) (record, *protocolError) {
+	slug, ok := h.slugOf(identifier)
+	if !ok {
+		return record{}, newProtocolError(errorIDDoesNotExist, "no such identifier: %s", identifier)
+	}
+
+	index, exists := h.repo.bySlug[slug]
+	if !exists {
+		return record{}, newProtocolError(errorIDDoesNotExist, "no such identifier: %s", identifier)
+	}
+
+	return h.repo.records[index], nil
+}
+
+// checkArguments returns an error if the request has arguments the verb
+// doesn't accept or repeats an argument.
+func checkArguments(verb string, args url.Values) *protocolError {
+	allowed := make(map[string]struct{})
+
+	for _, arg := range allowedArguments[verb] {
+		allowed[arg] = struct{}{}
+	}
+
+	for arg, values := range args {
+		if arg == "verb" {
+			continue
+		}
+
+		if _, isAllowed := allowed[arg]; !isAllowed {
+			return newProtocolError(errorBadArgument, "%s doesn't accept the argument %s", verb, arg)
+		}
+
+		if len(values) > 1 {
+			return newProtocolError(errorBadArgument, "the argument %s is repeated", arg)
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP handles an OAI-PMH request, which may be a GET or a POST.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	args := r.Form
+	verb := args.Get("verb")
+	base := baseURL(r)
+
+	resp := response{
+		Xmlns:          oaiNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: oaiSchemaLocation,
+		ResponseDate:   time.Now().UTC().Format(datestampLayout),
+		Request:        oaiRequest{BaseURL: base},
+	}
+
+	var protoErr *protocolError
+
+	switch _, isVerb := allowedArguments[verb]; {
+	case !isVerb || len(args["verb"]) > 1:
+		protoErr = newProtocolError(errorBadVerb, "illegal or missing verb")
+	default:
+		protoErr = checkArguments(verb, args)
+
+		if protoErr == nil {
+			protoErr = h.handleVerb(verb, args, &resp, base)
+		}
+	}
+
+	// The request element only echoes the arguments when there's no
+	// badVerb or badArgument error.
+	if protoErr == nil || (protoErr.code != errorBadVerb && protoErr.code != errorBadArgument) {
+		resp.Request = oaiRequest{
+			Verb:            verb,
+			Identifier:      args.Get("identifier"),
+			MetadataPrefix:  args.Get("metadataPrefix"),
+			From:            args.Get("from"),
+			Until:           args.Get("until"),
+			Set:             args.Get("set"),
+			ResumptionToken: args.Get("resumptionToken"),
+			BaseURL:         base,
+		}
+	}
+
+	if protoErr != nil {
+		resp.Errors = []oaiError{{Code: protoErr.code, Message: protoErr.message}}
+		resp.Identify, resp.ListMetadataFormats, resp.ListSets = nil, nil, nil
+		resp.GetRecord, resp.ListIdentifiers, resp.ListRecords = nil, nil, nil
+	}
+
+	marshalledResponse, err := xml.MarshalIndent(resp, "", indent)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger.LogDebug(fmt.Sprintf("Handled OAI-PMH request: %s", r.URL.RawQuery), logger.Phase("serve"))
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+
+	if _, err := fmt.Fprintf(w, "%s%s\n", xml.Header, marshalledResponse); err != nil {
+		logger.LogError(err, logger.Phase("serve"))
+	}
+}
@@ -0,0 +1,391 @@
+package oai
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func testArtifact(slug string, date time.Time, identity string, decade int) parse.Artifact {
+	return testutil.Artifact(slug, date, parse.GenericEntry{
+		string(parse.FieldLinks): []interface{}{
+			map[string]interface{}{"name": "Website", "url": "https://example.com/" + slug},
+		},
+		string(parse.FieldPeople):     []interface{}{"Someone"},
+		string(parse.FieldIdentities): []interface{}{identity},
+		string(parse.FieldFromYear):   decade,
+		string(parse.FieldDecades):    []interface{}{decade},
+	})
+}
+
+func day(month time.Month, dayOfMonth int) time.Time {
+	return time.Date(2022, month, dayOfMonth, 12, 0, 0, 0, time.UTC)
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	artifacts := []parse.Artifact{
+		testArtifact("first", day(time.January, 1), "asexual", 1970),
+		testArtifact("first", day(time.March, 1), "asexual", 1970),
+		testArtifact("second", day(time.February, 1), "aromantic", 1990),
+		testArtifact("third", day(time.April, 1), "asexual", 1990),
+	}
+
+	handler := NewHandler(artifacts, Options{
+		Name:       "Test Archive",
+		Identifier: "example.com",
+		AdminEmail: "admin@example.com",
+		PageSize:   2,
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// request makes a GET request with the given query and decodes the response.
+func request(t *testing.T, server *httptest.Server, query string) response {
+	t.Helper()
+
+	resp, err := http.Get(server.URL + "/?" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return decodeResponse(t, resp)
+}
+
+func decodeResponse(t *testing.T, resp *http.Response) response {
+	t.Helper()
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded response
+
+	if err := xml.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("%s\n%s", err, body)
+	}
+
+	return decoded
+}
+
+func errorCode(resp response) string {
+	if len(resp.Errors) == 0 {
+		return ""
+	}
+
+	return resp.Errors[0].Code
+}
+
+func TestIdentify(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := request(t, server, "verb=Identify")
+
+	if code := errorCode(resp); code != "" {
+		t.Fatalf("error = %s", code)
+	}
+
+	want := identify{
+		RepositoryName:    "Test Archive",
+		BaseURL:           server.URL + "/",
+		ProtocolVersion:   protocolVersion,
+		AdminEmail:        "admin@example.com",
+		EarliestDatestamp: "2022-01-01T12:00:00Z",
+		DeletedRecord:     "no",
+		Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+	}
+
+	if resp.Identify == nil || *resp.Identify != want {
+		t.Errorf("Identify = %+v, want %+v", resp.Identify, want)
+	}
+
+	if resp.Request.Verb != "Identify" {
+		t.Errorf("request verb = %q, want Identify", resp.Request.Verb)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode string
+		wantVerb string
+	}{
+		{name: "missing verb", query: "", wantCode: errorBadVerb},
+		{name: "unknown verb", query: "verb=Delete", wantCode: errorBadVerb},
+		{name: "repeated verb", query: "verb=Identify&verb=Identify", wantCode: errorBadVerb},
+		{name: "illegal argument", query: "verb=Identify&metadataPrefix=oai_dc", wantCode: errorBadArgument},
+		{name: "repeated argument", query: "verb=GetRecord&identifier=a&identifier=b&metadataPrefix=oai_dc", wantCode: errorBadArgument},
+		{name: "missing identifier", query: "verb=GetRecord&metadataPrefix=oai_dc", wantCode: errorBadArgument},
+		{name: "missing metadata prefix", query: "verb=ListRecords", wantCode: errorBadArgument},
+		{
+			name:     "unknown metadata prefix",
+			query:    "verb=GetRecord&identifier=oai:example.com:first&metadataPrefix=marc21",
+			wantCode: errorCannotDisseminateFormat,
+			wantVerb: "GetRecord",
+		},
+		{
+			name:     "unknown identifier",
+			query:    "verb=GetRecord&identifier=oai:example.com:missing&metadataPrefix=oai_dc",
+			wantCode: errorIDDoesNotExist,
+			wantVerb: "GetRecord",
+		},
+		{
+			name:     "identifier in another namespace",
+			query:    "verb=ListMetadataFormats&identifier=oai:example.org:first",
+			wantCode: errorIDDoesNotExist,
+			wantVerb: "ListMetadataFormats",
+		},
+		{name: "invalid from", query: "verb=ListIdentifiers&metadataPrefix=oai_dc&from=yesterday", wantCode: errorBadArgument},
+		{
+			name:     "mixed granularity",
+			query:    "verb=ListIdentifiers&metadataPrefix=oai_dc&from=2022-01-01&until=2022-02-01T00:00:00Z",
+			wantCode: errorBadArgument,
+		},
+		{name: "from after until", query: "verb=ListIdentifiers&metadataPrefix=oai_dc&from=2022-02-01&until=2022-01-01", wantCode: errorBadArgument},
+		{
+			name:     "no records match",
+			query:    "verb=ListIdentifiers&metadataPrefix=oai_dc&set=decade:2000",
+			wantCode: errorNoRecordsMatch,
+			wantVerb: "ListIdentifiers",
+		},
+		{
+			name:     "invalid resumption token",
+			query:    "verb=ListIdentifiers&resumptionToken=not-a-token",
+			wantCode: errorBadResumptionToken,
+			wantVerb: "ListIdentifiers",
+		},
+		{
+			name:     "resumption token with other arguments",
+			query:    "verb=ListIdentifiers&metadataPrefix=oai_dc&resumptionToken=" + encodeToken(listArguments{offset: 2}),
+			wantCode: errorBadArgument,
+		},
+		{
+			name:     "resumption token past the end",
+			query:    "verb=ListRecords&resumptionToken=" + encodeToken(listArguments{offset: 10}),
+			wantCode: errorBadResumptionToken,
+			wantVerb: "ListRecords",
+		},
+		{name: "resumption token for ListSets", query: "verb=ListSets&resumptionToken=abc", wantCode: errorBadResumptionToken, wantVerb: "ListSets"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := request(t, server, test.query)
+
+			if code := errorCode(resp); code != test.wantCode {
+				t.Errorf("error = %q, want %q", code, test.wantCode)
+			}
+
+			// The request element only echoes the arguments when they're
+			// valid.
+			if resp.Request.Verb != test.wantVerb {
+				t.Errorf("request verb = %q, want %q", resp.Request.Verb, test.wantVerb)
+			}
+
+			if resp.Identify != nil || resp.GetRecord != nil || resp.ListIdentifiers != nil || resp.ListRecords != nil {
+				t.Errorf("error response has a body: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestGetRecord(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := request(t, server, "verb=GetRecord&identifier=oai:example.com:first&metadataPrefix=oai_dc")
+
+	if code := errorCode(resp); code != "" {
+		t.Fatalf("error = %s", code)
+	}
+
+	want := header{
+		Identifier: "oai:example.com:first",
+		Datestamp:  "2022-03-01T12:00:00Z",
+		SetSpecs:   []string{"identity:asexual", "decade:1970"},
+	}
+
+	if resp.GetRecord == nil || !reflect.DeepEqual(resp.GetRecord.Record.Header, want) {
+		t.Errorf("header = %+v, want %+v", resp.GetRecord, want)
+	}
+}
+
+func TestListSets(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := request(t, server, "verb=ListSets")
+
+	if resp.ListSets == nil {
+		t.Fatalf("no ListSets in response: %+v", resp)
+	}
+
+	specs := make([]string, len(resp.ListSets.Sets))
+
+	for i, s := range resp.ListSets.Sets {
+		specs[i] = s.SetSpec
+	}
+
+	want := []string{"identity", "decade", "decade:1970", "decade:1990", "identity:aromantic", "identity:asexual"}
+
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("sets = %v, want %v", specs, want)
+	}
+}
+
+func identifiers(headers []header) []string {
+	ids := make([]string, len(headers))
+
+	for i, h := range headers {
+		ids[i] = strings.TrimPrefix(h.Identifier, "oai:example.com:")
+	}
+
+	return ids
+}
+
+func TestListIdentifiersResumption(t *testing.T) {
+	server := newTestServer(t)
+
+	first := request(t, server, "verb=ListIdentifiers&metadataPrefix=oai_dc")
+
+	if first.ListIdentifiers == nil {
+		t.Fatalf("no ListIdentifiers in response: %+v", first)
+	}
+
+	if got, want := identifiers(first.ListIdentifiers.Headers), []string{"second", "first"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first page = %v, want %v", got, want)
+	}
+
+	token := first.ListIdentifiers.ResumptionToken
+	if token == nil || token.Token == "" || token.CompleteListSize != 3 || token.Cursor != 0 {
+		t.Fatalf("first resumption token = %+v", token)
+	}
+
+	second := request(t, server, "verb=ListIdentifiers&resumptionToken="+url.QueryEscape(token.Token))
+
+	if second.ListIdentifiers == nil {
+		t.Fatalf("no ListIdentifiers in response: %+v", second)
+	}
+
+	if got, want := identifiers(second.ListIdentifiers.Headers), []string{"third"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second page = %v, want %v", got, want)
+	}
+
+	// The last page of an incomplete list has an empty resumption token.
+	last := second.ListIdentifiers.ResumptionToken
+	if last == nil || last.Token != "" || last.CompleteListSize != 3 || last.Cursor != 2 {
+		t.Errorf("last resumption token = %+v", last)
+	}
+}
+
+func TestListRecordsKeepsArgumentsAcrossPages(t *testing.T) {
+	server := newTestServer(t)
+
+	first := request(t, server, "verb=ListRecords&metadataPrefix=oai_dc&set=identity:asexual&from=2022-03-01")
+
+	if first.ListRecords == nil {
+		t.Fatalf("no ListRecords in response: %+v", first)
+	}
+
+	headers := make([]header, len(first.ListRecords.Records))
+
+	for i, rec := range first.ListRecords.Records {
+		headers[i] = rec.Header
+	}
+
+	if got, want := identifiers(headers), []string{"first", "third"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %v, want %v", got, want)
+	}
+
+	// Everything fits on one page, so there's no resumption token.
+	if first.ListRecords.ResumptionToken != nil {
+		t.Errorf("resumption token = %+v, want none", first.ListRecords.ResumptionToken)
+	}
+
+}
+
+func TestResumptionTokenRoundTrip(t *testing.T) {
+	from := day(time.March, 1)
+	want := listArguments{from: &from, until: nil, set: "decade:1990", offset: 2}
+
+	got, err := decodeToken(encodeToken(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeToken() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUntilIncludesTheWholeDay(t *testing.T) {
+	server := newTestServer(t)
+
+	resp := request(t, server, "verb=ListIdentifiers&metadataPrefix=oai_dc&until=2022-02-01")
+
+	if resp.ListIdentifiers == nil {
+		t.Fatalf("no ListIdentifiers in response: %+v", resp)
+	}
+
+	if got, want := identifiers(resp.ListIdentifiers.Headers), []string{"second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("identifiers = %v, want %v", got, want)
+	}
+}
+
+func TestPost(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.PostForm(server.URL, url.Values{"verb": {"ListMetadataFormats"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := decodeResponse(t, resp)
+
+	if decoded.ListMetadataFormats == nil || len(decoded.ListMetadataFormats.Formats) != 1 ||
+		decoded.ListMetadataFormats.Formats[0].MetadataPrefix != metadataPrefix {
+		t.Errorf("ListMetadataFormats = %+v", decoded.ListMetadataFormats)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server := newTestServer(t)
+
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/?verb=Identify", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,126 @@
+package oai
+
+import (
+	"encoding/xml"
+
+	"github.com/acearchive/artifact-action/metadata"
+)
+
+const (
+	oaiNamespace      = "http://www.openarchives.org/OAI/2.0/"
+	xsiNamespace      = "http://www.w3.org/2001/XMLSchema-instance"
+	oaiSchemaLocation = "http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+	protocolVersion   = "2.0"
+	datestampLayout   = "2006-01-02T15:04:05Z"
+	dayLayout         = "2006-01-02"
+	metadataPrefix    = "oai_dc"
+	oaiDCSchema       = "http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	oaiDCNamespace    = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	indent            = "  "
+)
+
+// These are the error codes defined by the protocol.
+const (
+	errorBadArgument             = "badArgument"
+	errorBadResumptionToken      = "badResumptionToken"
+	errorBadVerb                 = "badVerb"
+	errorCannotDisseminateFormat = "cannotDisseminateFormat"
+	errorIDDoesNotExist          = "idDoesNotExist"
+	errorNoRecordsMatch          = "noRecordsMatch"
+)
+
+type oaiError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+type oaiRequest struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	BaseURL         string `xml:",chardata"`
+}
+
+type identify struct {
+	RepositoryName    string `xml:"repositoryName"`
+	BaseURL           string `xml:"baseURL"`
+	ProtocolVersion   string `xml:"protocolVersion"`
+	AdminEmail        string `xml:"adminEmail"`
+	EarliestDatestamp string `xml:"earliestDatestamp"`
+	DeletedRecord     string `xml:"deletedRecord"`
+	Granularity       string `xml:"granularity"`
+}
+
+type metadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+type listMetadataFormats struct {
+	Formats []metadataFormat `xml:"metadataFormat"`
+}
+
+type setElement struct {
+	SetSpec string `xml:"setSpec"`
+	SetName string `xml:"setName"`
+}
+
+type listSets struct {
+	Sets []setElement `xml:"set"`
+}
+
+type header struct {
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpecs   []string `xml:"setSpec"`
+}
+
+type recordMetadata struct {
+	DublinCore metadata.DublinCore `xml:"oai_dc:dc"`
+}
+
+type recordElement struct {
+	Header   header         `xml:"header"`
+	Metadata recordMetadata `xml:"metadata"`
+}
+
+type resumptionToken struct {
+	CompleteListSize int    `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+	Token            string `xml:",chardata"`
+}
+
+type getRecord struct {
+	Record recordElement `xml:"record"`
+}
+
+type listIdentifiers struct {
+	Headers         []header         `xml:"header"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken"`
+}
+
+type listRecords struct {
+	Records         []recordElement  `xml:"record"`
+	ResumptionToken *resumptionToken `xml:"resumptionToken"`
+}
+
+type response struct {
+	XMLName             xml.Name             `xml:"OAI-PMH"`
+	Xmlns               string               `xml:"xmlns,attr"`
+	Xsi                 string               `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string               `xml:"xsi:schemaLocation,attr"`
+	ResponseDate        string               `xml:"responseDate"`
+	Request             oaiRequest           `xml:"request"`
+	Errors              []oaiError           `xml:"error"`
+	Identify            *identify            `xml:"Identify"`
+	ListMetadataFormats *listMetadataFormats `xml:"ListMetadataFormats"`
+	ListSets            *listSets            `xml:"ListSets"`
+	GetRecord           *getRecord           `xml:"GetRecord"`
+	ListIdentifiers     *listIdentifiers     `xml:"ListIdentifiers"`
+	ListRecords         *listRecords         `xml:"ListRecords"`
+}
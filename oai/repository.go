@@ -0,0 +1,158 @@
+package oai
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/metadata"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const (
+	identitySetSpec = "identity"
+	decadeSetSpec   = "decade"
+)
+
+var setSpecUnsafePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Options configures the repository.
+type Options struct {
+	// Name is the human-readable name of the repository.
+	Name string
+
+	// Identifier is the namespace of the OAI identifier of each record, like
+	// `oai:<Identifier>:<slug>`. It's usually the domain name of the site.
+	Identifier string
+
+	// AdminEmail is the email address of the administrator of the
+	// repository.
+	AdminEmail string
+
+	// PageSize is the maximum number of records or identifiers in a single
+	// response.
+	PageSize int
+
+	// Metadata configures the `oai_dc` metadata of each record.
+	Metadata metadata.Options
+}
+
+// set is a set of records.
+type set struct {
+	Spec string
+	Name string
+}
+
+// record is an item in the repository.
+type record struct {
+	Slug       string
+	Datestamp  time.Time
+	SetSpecs   []string
+	DublinCore metadata.DublinCore
+}
+
+// repository is every record in the repository, from least to most recently
+// modified, along with every set.
+type repository struct {
+	records  []record
+	bySlug   map[string]int
+	sets     []set
+	earliest time.Time
+}
+
+func setSpecPart(value string) string {
+	return strings.Trim(setSpecUnsafePattern.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+// newRepository returns the repository of the artifacts in the history
+// `artifacts`. The datestamp of each record is the date of the commit which
+// last modified it.
+func newRepository(artifacts []parse.Artifact, opts Options) *repository {
+	histories := parse.GroupBySlug(artifacts)
+
+	repo := &repository{
+		records:  make([]record, 0, len(histories)),
+		bySlug:   make(map[string]int, len(histories)),
+		sets:     []set{{Spec: identitySetSpec, Name: "Identities"}, {Spec: decadeSetSpec, Name: "Decades"}},
+		earliest: time.Time{},
+	}
+
+	setNames := make(map[string]string)
+
+	for _, history := range histories {
+		if history.LastModified == nil {
+			continue
+		}
+
+		metadataRecord, err := metadata.NewRecord(history.Latest, opts.Metadata)
+		if err != nil {
+			// Artifacts which can't be described in `oai_dc` aren't in the
+			// repository.
+			continue
+		}
+
+		rec := record{
+			Slug:       history.Latest.Slug,
+			Datestamp:  history.LastModified.Date.UTC().Truncate(time.Second),
+			SetSpecs:   nil,
+			DublinCore: metadata.NewDublinCore(metadataRecord, opts.Metadata),
+		}
+
+		for _, identity := range metadataRecord.Entry.Identities {
+			spec := fmt.Sprintf("%s:%s", identitySetSpec, setSpecPart(identity))
+			setNames[spec] = identity
+			rec.SetSpecs = append(rec.SetSpecs, spec)
+		}
+
+		for _, decade := range metadataRecord.Entry.Decades {
+			spec := fmt.Sprintf("%s:%d", decadeSetSpec, decade)
+			setNames[spec] = fmt.Sprintf("%ds", decade)
+			rec.SetSpecs = append(rec.SetSpecs, spec)
+		}
+
+		if firstSeen := history.FirstSeen.Date.UTC(); repo.earliest.IsZero() || firstSeen.Before(repo.earliest) {
+			repo.earliest = firstSeen.Truncate(time.Second)
+		}
+
+		repo.records = append(repo.records, rec)
+	}
+
+	sort.Slice(repo.records, func(i, j int) bool {
+		if !repo.records[i].Datestamp.Equal(repo.records[j].Datestamp) {
+			return repo.records[i].Datestamp.Before(repo.records[j].Datestamp)
+		}
+
+		return repo.records[i].Slug < repo.records[j].Slug
+	})
+
+	for i, rec := range repo.records {
+		repo.bySlug[rec.Slug] = i
+	}
+
+	specs := make([]string, 0, len(setNames))
+
+	for spec := range setNames {
+		specs = append(specs, spec)
+	}
+
+	sort.Strings(specs)
+
+	for _, spec := range specs {
+		repo.sets = append(repo.sets, set{Spec: spec, Name: setNames[spec]})
+	}
+
+	return repo
+}
+
+// inSet returns whether `rec` is in the set `spec` or one of its subsets.
+func (r record) inSet(spec string) bool {
+	for _, recordSpec := range r.SetSpecs {
+		if recordSpec == spec || strings.HasPrefix(recordSpec, spec+":") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,290 @@
+package output
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+const delimiterRandomBytes = 16
+
+// openEnvFile opens the file at the path in the given environment variable
+// for appending. It returns `nil` if the environment variable isn't set.
+func openEnvFile(envVar string) (*os.File, error) {
+	path := os.Getenv(envVar)
+	if path == "" {
+		return nil, nil
+	}
+
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+}
+
+func newDelimiter(value string) (string, error) {
+	for {
+		randomBytes := make([]byte, delimiterRandomBytes)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return "", err
+		}
+
+		delimiter := fmt.Sprintf("ghadelimiter_%s", hex.EncodeToString(randomBytes))
+
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+type actionOutput struct {
+	name  string
+	value string
+}
+
+// setOutputs sets the outputs of the GitHub Action by writing them to the
+// file at `$GITHUB_OUTPUT`. Each value is written using a heredoc-style
+// delimiter so that it may contain newlines. If `$GITHUB_OUTPUT` isn't set,
+// the outputs are skipped with a warning rather than using the deprecated
+// `set-output` workflow command, which GitHub has disabled.
+func setOutputs(outputs []actionOutput) (err error) {
+	outputFile, err := openEnvFile("GITHUB_OUTPUT")
+	if err != nil {
+		return err
+	}
+
+	if outputFile == nil {
+		logger.LogWarning("The outputs of the action were not set, because `$GITHUB_OUTPUT` isn't set. This requires a newer version of the GitHub Actions runner.")
+
+		return nil
+	}
+
+	defer func() {
+		if closeErr := outputFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for _, output := range outputs {
+		delimiter, err := newDelimiter(output.value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(outputFile, "%s<<%s\n%s\n%s\n", output.name, delimiter, output.value, delimiter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// outputDir returns the directory that outputs which are written to files are
+// written to.
+func outputDir() string {
+	if dir := cfg.OutputDir(); dir != "" {
+		return dir
+	}
+
+	if dir := os.Getenv("RUNNER_TEMP"); dir != "" {
+		return dir
+	}
+
+	return os.TempDir()
+}
+
+// writeOutputFile writes `value` to a file with the given name in the output
+// directory and returns its path.
+func writeOutputFile(filename, value string) (string, error) {
+	if err := os.MkdirAll(outputDir(), 0o755); err != nil {
+		return "", err
+	}
+
+	outputPath := filepath.Join(outputDir(), filename)
+
+	if err := os.WriteFile(outputPath, []byte(value), 0o644); err != nil { //nolint:gosec
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// spillOutput writes the value of an output to a file if it's larger than the
+// configured threshold or if outputs are always supposed to be written to
+// files. When the value is written to a file, an additional `<name>-file`
+// output contains its path. When the value is over the threshold, it's only
+// written to the file, because GitHub limits the size of outputs.
+func spillOutput(name, value string) ([]actionOutput, error) {
+	oversized := len(value) > cfg.OutputThreshold()
+
+	if !oversized && !cfg.OutputFiles() {
+		return []actionOutput{{name: name, value: value}}, nil
+	}
+
+	outputPath, err := writeOutputFile(fmt.Sprintf("%s.json", name), value)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []actionOutput{{name: fmt.Sprintf("%s-file", name), value: outputPath}}
+
+	if oversized {
+		logger.LogNotice(fmt.Sprintf("The `%s` output is %d bytes, so it was only written to the file in the `%s-file` output: %s", name, len(value), name, outputPath))
+	} else {
+		outputs = append(outputs, actionOutput{name: name, value: value})
+	}
+
+	return outputs, nil
+}
+
+// printAction sets the outputs of the GitHub Action and writes the job
+// summary.
+func printAction(output Output, cidList []cid.Cid) error {
+	artifactOutput, err := marshalArtifact(output, false)
+	if err != nil {
+		return err
+	}
+
+	cidOutput, err := marshalCid(cidList, false)
+	if err != nil {
+		return err
+	}
+
+	artifactOutputs, err := spillOutput("artifacts", artifactOutput)
+	if err != nil {
+		return err
+	}
+
+	cidOutputs, err := spillOutput("cids", cidOutput)
+	if err != nil {
+		return err
+	}
+
+	outputs := append(artifactOutputs, cidOutputs...)
+
+	if output.RootCid != nil {
+		outputs = append(outputs, actionOutput{name: "root", value: *output.RootCid})
+	}
+
+	if cfg.Sarif() {
+		sarifOutput, err := marshalSarif(output, false)
+		if err != nil {
+			return err
+		}
+
+		sarifPath, err := writeOutputFile("results.sarif", sarifOutput)
+		if err != nil {
+			return err
+		}
+
+		outputs = append(outputs, actionOutput{name: "sarif-file", value: sarifPath})
+	}
+
+	if err := setOutputs(outputs); err != nil {
+		return err
+	}
+
+	return writeStepSummary(output, cidList)
+}
+
+func writeValidationFailures(builder *strings.Builder, invalid []error) {
+	for _, invalidErr := range invalid {
+		var (
+			parseErr   parse.ArtifactParseError
+			invalidArt parse.InvalidArtifactError
+		)
+
+		switch {
+		case errors.As(invalidErr, &invalidArt):
+			fmt.Fprintf(builder, "- `%s`\n", invalidArt.FilePath)
+
+			for _, reason := range invalidArt.Reasons {
+				fmt.Fprintf(builder, "  - %s %s\n", reason.Field.Literal(), reason.Reason)
+			}
+		case errors.As(invalidErr, &parseErr):
+			fmt.Fprintf(builder, "- `%s`: %s\n", parseErr.Path, parseErr.Reason)
+		default:
+			fmt.Fprintf(builder, "- %s\n", invalidErr.Error())
+		}
+	}
+}
+
+// stepSummary renders the Markdown job summary for a run.
+func stepSummary(output Output, cidList []cid.Cid) string {
+	var builder strings.Builder
+
+	builder.WriteString("## Ace Archive\n\n")
+
+	builder.WriteString("| | Count |\n")
+	builder.WriteString("| --- | ---: |\n")
+	fmt.Fprintf(&builder, "| Artifact files | %d |\n", len(output.Artifacts))
+	fmt.Fprintf(&builder, "| Unique CIDs | %d |\n", len(cidList))
+
+	if len(output.Invalid) > 0 {
+		fmt.Fprintf(&builder, "| Invalid artifact files | %d |\n", len(output.Invalid))
+	}
+
+	if output.PinStats != nil {
+		fmt.Fprintf(&builder, "| Files already pinned | %d |\n", output.PinStats.AlreadyPinned)
+		if cfg.DryRun() {
+			fmt.Fprintf(&builder, "| Files which would be pinned | %d |\n", output.PinStats.ToPin)
+		} else {
+			fmt.Fprintf(&builder, "| Files newly pinned | %d |\n", len(output.PinStats.Pinned))
+		}
+	}
+
+	builder.WriteString("\n")
+
+	if output.RootCid != nil {
+		fmt.Fprintf(&builder, "**Root directory:** `%s`\n\n", *output.RootCid)
+	}
+
+	if output.PinStats != nil {
+		if cfg.DryRun() {
+			builder.WriteString("This was a dry run. No files were actually pinned.\n\n")
+		}
+
+		if len(output.PinStats.Pinned) > 0 {
+			builder.WriteString("### Newly pinned files\n\n")
+
+			for _, pinnedCid := range output.PinStats.Pinned {
+				fmt.Fprintf(&builder, "- `%s`\n", pinnedCid.String())
+			}
+
+			builder.WriteString("\n")
+		}
+	}
+
+	if len(output.Invalid) > 0 {
+		builder.WriteString("### Validation failures\n\n")
+		writeValidationFailures(&builder, output.Invalid)
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// writeStepSummary appends the Markdown job summary for a run to the file at
+// `$GITHUB_STEP_SUMMARY`, if there is one.
+func writeStepSummary(output Output, cidList []cid.Cid) (err error) {
+	summaryFile, err := openEnvFile("GITHUB_STEP_SUMMARY")
+	if err != nil || summaryFile == nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := summaryFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = summaryFile.WriteString(stepSummary(output, cidList))
+
+	return err
+}
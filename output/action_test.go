@@ -0,0 +1,281 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/pin"
+	"github.com/ipfs/go-cid"
+	"github.com/spf13/viper"
+)
+
+// setConfig sets a config value for the duration of the test.
+func setConfig(t *testing.T, key string, value interface{}) {
+	t.Helper()
+
+	viper.Set(key, value)
+	t.Cleanup(func() { viper.Set(key, nil) })
+}
+
+func TestSpillOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		outputFiles bool
+		wantNames   []string
+	}{
+		{
+			name:        "under the threshold",
+			value:       "small",
+			outputFiles: false,
+			wantNames:   []string{"artifacts"},
+		},
+		{
+			name:        "over the threshold",
+			value:       strings.Repeat("x", 11),
+			outputFiles: false,
+			wantNames:   []string{"artifacts-file"},
+		},
+		{
+			name:        "always written to files",
+			value:       "small",
+			outputFiles: true,
+			wantNames:   []string{"artifacts-file", "artifacts"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			setConfig(t, "output-dir", dir)
+			setConfig(t, "output-threshold", 10)
+			setConfig(t, "output-files", test.outputFiles)
+
+			outputs, err := spillOutput("artifacts", test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := make([]string, len(outputs))
+
+			for i, output := range outputs {
+				names[i] = output.name
+
+				switch output.name {
+				case "artifacts":
+					if output.value != test.value {
+						t.Errorf("artifacts = %q, want %q", output.value, test.value)
+					}
+				case "artifacts-file":
+					if want := filepath.Join(dir, "artifacts.json"); output.value != want {
+						t.Errorf("artifacts-file = %q, want %q", output.value, want)
+					}
+
+					contents, err := os.ReadFile(output.value)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if string(contents) != test.value {
+						t.Errorf("file contains %q, want %q", contents, test.value)
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(names, test.wantNames) {
+				t.Errorf("outputs = %v, want %v", names, test.wantNames)
+			}
+		})
+	}
+}
+
+// parseOutputFile parses the heredoc-style outputs in a `$GITHUB_OUTPUT` file.
+func parseOutputFile(t *testing.T, contents string) map[string]string {
+	t.Helper()
+
+	outputs := make(map[string]string)
+	lines := strings.Split(strings.TrimSuffix(contents, "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		name, delimiter, found := strings.Cut(lines[i], "<<")
+		if !found {
+			t.Fatalf("line %d is not the start of a heredoc: %q", i+1, lines[i])
+		}
+
+		var value []string
+
+		for i++; i < len(lines) && lines[i] != delimiter; i++ {
+			value = append(value, lines[i])
+		}
+
+		if i == len(lines) {
+			t.Fatalf("the heredoc for %s is never closed", name)
+		}
+
+		outputs[name] = strings.Join(value, "\n")
+	}
+
+	return outputs
+}
+
+func TestSetOutputs(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "output")
+	t.Setenv("GITHUB_OUTPUT", outputPath)
+
+	want := map[string]string{
+		"artifacts": "{\n  \"artifacts\": []\n}",
+		"root":      "bafybeihsf4562gmmyoya7eh5buxv65lqcdoil3wsi5jf5fceskap7yzooi",
+		"tricky":    "value<<EOF\nEOF\nghadelimiter_",
+	}
+
+	outputs := []actionOutput{
+		{name: "artifacts", value: want["artifacts"]},
+		{name: "root", value: want["root"]},
+		{name: "tricky", value: want["tricky"]},
+	}
+
+	if err := setOutputs(outputs); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := parseOutputFile(t, string(contents)); !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs = %q, want %q", got, want)
+	}
+}
+
+func TestSetOutputsWithoutOutputFile(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+
+	if err := setOutputs([]actionOutput{{name: "root", value: "value"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewDelimiter(t *testing.T) {
+	value := "line\nghadelimiter_\nline"
+
+	delimiter, err := newDelimiter(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(delimiter, "ghadelimiter_") || delimiter == "ghadelimiter_" {
+		t.Errorf("delimiter = %q, want a random suffix", delimiter)
+	}
+
+	if strings.Contains(value, delimiter) {
+		t.Errorf("delimiter %q is in the value", delimiter)
+	}
+}
+
+func TestStepSummary(t *testing.T) {
+	rootCid := "bafybeihsf4562gmmyoya7eh5buxv65lqcdoil3wsi5jf5fceskap7yzooi"
+
+	pinnedCid, err := cid.Decode("bafybeib2fu4qf44xiyduvhadog5raukc3ajdnd4qpsavyxaa2umzjeif5y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := Output{
+		Artifacts: []parse.Artifact{{Slug: "first"}, {Slug: "second"}},
+		RootCid:   &rootCid,
+		Invalid: []error{
+			parse.InvalidArtifactError{
+				FilePath: "artifacts/broken.md",
+				Reasons:  []parse.InvalidArtifactReason{{Rule: "title", Field: parse.FieldTitle, Reason: "must not be empty"}},
+			},
+			parse.ArtifactParseError{Path: "artifacts/unparseable.md", Reason: "no front matter"},
+		},
+		PinStats: &pin.Stats{
+			AlreadyPinned: 3,
+			ToPin:         1,
+			Pinned:        []cid.Cid{pinnedCid},
+			RootPinned:    true,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		dryRun    bool
+		want      []string
+		wantNotIn []string
+	}{
+		{
+			name:   "pinned",
+			dryRun: false,
+			want: []string{
+				"| Artifact files | 2 |",
+				"| Unique CIDs | 1 |",
+				"| Invalid artifact files | 2 |",
+				"| Files already pinned | 3 |",
+				"| Files newly pinned | 1 |",
+				"**Root directory:** `" + rootCid + "`",
+				"### Newly pinned files\n\n- `" + pinnedCid.String() + "`",
+				"- `artifacts/broken.md`\n  - `title` must not be empty",
+				"- `artifacts/unparseable.md`: no front matter",
+			},
+			wantNotIn: []string{"dry run"},
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+			want: []string{
+				"| Files which would be pinned | 1 |",
+				"This was a dry run. No files were actually pinned.",
+			},
+			wantNotIn: []string{"Files newly pinned"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setConfig(t, "dry-run", test.dryRun)
+
+			summary := stepSummary(output, []cid.Cid{pinnedCid})
+
+			for _, want := range test.want {
+				if !strings.Contains(summary, want) {
+					t.Errorf("summary doesn't contain %q:\n%s", want, summary)
+				}
+			}
+
+			for _, unwanted := range test.wantNotIn {
+				if strings.Contains(summary, unwanted) {
+					t.Errorf("summary contains %q:\n%s", unwanted, summary)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteStepSummaryAppends(t *testing.T) {
+	summaryPath := filepath.Join(t.TempDir(), "summary")
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryPath)
+
+	if err := os.WriteFile(summaryPath, []byte("Previous step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeStepSummary(Output{Artifacts: nil, RootCid: nil, Invalid: nil, PinStats: nil}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(contents), "Previous step\n## Ace Archive\n") {
+		t.Errorf("summary = %q, want it appended to the existing file", contents)
+	}
+}
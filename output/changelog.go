@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// changelogSections is the heading of each section of the changelog, in
+// order.
+var changelogSections = []struct {
+	kind    parse.ChangeKind
+	heading string
+}{
+	{kind: parse.ChangeNew, heading: "New artifacts"},
+	{kind: parse.ChangeNewFiles, heading: "New files added"},
+	{kind: parse.ChangeCorrection, heading: "Corrections"},
+	{kind: parse.ChangeRemoved, heading: "Removed"},
+}
+
+func changelogItem(change parse.Change) string {
+	var builder strings.Builder
+
+	if change.Title != "" {
+		fmt.Fprintf(&builder, "- %s (`%s`)", change.Title, change.Slug)
+	} else {
+		fmt.Fprintf(&builder, "- `%s`", change.Slug)
+	}
+
+	if len(change.NewFiles) > 0 {
+		fmt.Fprintf(&builder, ": added `%s`", strings.Join(change.NewFiles, "`, `"))
+	}
+
+	for _, subject := range change.Subjects {
+		fmt.Fprintf(&builder, "\n  - %s", subject)
+	}
+
+	return builder.String()
+}
+
+// WriteChangelog writes the changes between the commits `from` and `to` to
+// `w` as Markdown, grouped by how each artifact changed.
+func WriteChangelog(w io.Writer, from, to string, changes []parse.Change) error {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "# Changes from %s to %s\n", from, to)
+
+	if len(changes) == 0 {
+		builder.WriteString("\nNo artifacts changed.\n")
+	}
+
+	for _, section := range changelogSections {
+		var items []string
+
+		for _, change := range changes {
+			if change.Kind == section.kind {
+				items = append(items, changelogItem(change))
+			}
+		}
+
+		if len(items) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "\n## %s\n\n%s\n", section.heading, strings.Join(items, "\n"))
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
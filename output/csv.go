@@ -0,0 +1,32 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/table"
+)
+
+// writeTable writes the configured table to `w` as delimiter-separated
+// values.
+func writeTable(w io.Writer, artifacts []parse.Artifact, delimiter rune) error {
+	header, rows, err := table.Build(table.Name(cfg.Table()), artifacts, cfg.Columns())
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func TestWriteTable(t *testing.T) {
+	artifacts := []parse.Artifact{
+		{
+			Slug: "manifesto",
+			Entry: parse.GenericEntry{
+				"description": "A \"paper\", with\ttabs\nand newlines",
+				"people":      []interface{}{"Lisa Orlando", "New York Radical Feminists"},
+			},
+		},
+		{
+			Slug:  "zine",
+			Entry: parse.GenericEntry{"description": "Plain", "people": []interface{}{}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		delimiter rune
+		want      string
+	}{
+		{
+			name:      "csv",
+			delimiter: ',',
+			want: "slug,description,people\n" +
+				"manifesto,\"A \"\"paper\"\", with\ttabs\nand newlines\",Lisa Orlando; New York Radical Feminists\n" +
+				"zine,Plain,\n",
+		},
+		{
+			name:      "tsv",
+			delimiter: '\t',
+			want: "slug\tdescription\tpeople\n" +
+				"manifesto\t\"A \"\"paper\"\", with\ttabs\nand newlines\"\tLisa Orlando; New York Radical Feminists\n" +
+				"zine\tPlain\t\n",
+		},
+	}
+
+	setConfig(t, "table", "artifacts")
+	setConfig(t, "columns", []string{"slug", "description", "people"})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := writeTable(&buf, artifacts, test.delimiter); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != test.want {
+				t.Errorf("writeTable() =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"reflect"
 
 	"github.com/acearchive/artifact-action/cfg"
 	"github.com/acearchive/artifact-action/parse"
+	"github.com/acearchive/artifact-action/pin"
 	"github.com/ipfs/go-cid"
 )
 
@@ -18,6 +21,14 @@ var ErrInvalidOutput = errors.New("invalid output parameter")
 type Output struct {
 	Artifacts []parse.Artifact `json:"artifacts"`
 	RootCid   *string          `json:"rootCid"`
+
+	// Invalid contains the reason each invalid artifact file is invalid. It's
+	// only included in the job summary.
+	Invalid []error `json:"-"`
+
+	// PinStats describes what was pinned, if anything. It's only included in
+	// the job summary.
+	PinStats *pin.Stats `json:"-"`
 }
 
 // initializeNilSlicesOfValue accepts a struct and initializes any nil slices in
@@ -71,6 +82,20 @@ func marshalArtifact(output Output, pretty bool) (string, error) {
 	return string(marshalledOutput), nil
 }
 
+// groupedOutput is the `grouped` output, which is the `artifacts` output with
+// the artifacts grouped by slug.
+type groupedOutput struct {
+	Artifacts map[string]parse.ArtifactHistory `json:"artifacts"`
+	RootCid   *string                          `json:"rootCid"`
+}
+
+func marshalGrouped(output Output, pretty bool) (string, error) {
+	return marshalJSON(groupedOutput{
+		Artifacts: parse.GroupBySlug(output.Artifacts),
+		RootCid:   output.RootCid,
+	}, pretty)
+}
+
 func marshalCid(cids []cid.Cid, pretty bool) (string, error) {
 	var (
 		marshalledOutput []byte
@@ -96,52 +121,107 @@ func marshalCid(cids []cid.Cid, pretty bool) (string, error) {
 	return string(marshalledOutput), nil
 }
 
+// Print prints the configured output, with the artifacts and CIDs in the
+// configured order.
 func Print(output Output, cidList []cid.Cid) error {
+	output.Artifacts = sortArtifacts(output.Artifacts)
+	cidList = sortCids(cidList)
+
 	if cfg.Action() {
-		artifactOutput, err := marshalArtifact(output, false)
-		if err != nil {
-			return err
-		}
+		return printAction(output, cidList)
+	}
 
-		fmt.Printf("::set-output name=artifacts::%s\n", artifactOutput) //nolint:forbidigo
+	return withOutputWriter(func(w io.Writer) error {
+		return printOutput(w, output, cidList)
+	})
+}
 
-		cidOutput, err := marshalCid(cidList, false)
-		if err != nil {
-			return err
-		}
+// withOutputWriter calls `write` with the configured output file, or stdout if
+// there isn't one.
+func withOutputWriter(write func(w io.Writer) error) (err error) {
+	if cfg.OutputFile() == "" {
+		return write(os.Stdout)
+	}
 
-		fmt.Printf("::set-output name=cids::%s\n", cidOutput) //nolint:forbidigo
+	outputFile, err := os.Create(cfg.OutputFile())
+	if err != nil {
+		return err
+	}
 
-		if output.RootCid != nil {
-			fmt.Printf("::set-output name=root::%s\n", *output.RootCid) //nolint:forbidigo
+	defer func() {
+		if closeErr := outputFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
 		}
+	}()
 
-		return nil
-	}
+	return write(outputFile)
+}
+
+func printOutput(w io.Writer, output Output, cidList []cid.Cid) error {
+	var err error
 
 	switch outputMode := cfg.Output(); outputMode {
 	case cfg.OutputArtifacts:
-		artifactOutput, err := marshalArtifact(output, true)
-		if err != nil {
-			return err
-		}
+		var artifactOutput string
 
-		fmt.Println(artifactOutput) //nolint:forbidigo
-	case cfg.OutputCids:
-		cidOutput, err := marshalCid(cidList, true)
-		if err != nil {
-			return err
+		if artifactOutput, err = marshalArtifact(output, true); err == nil {
+			_, err = fmt.Fprintln(w, artifactOutput)
 		}
+	case cfg.OutputCids:
+		var cidOutput string
 
-		fmt.Println(cidOutput) //nolint:forbidigo
+		if cidOutput, err = marshalCid(cidList, true); err == nil {
+			_, err = fmt.Fprintln(w, cidOutput)
+		}
 	case cfg.OutputRoot:
 		if output.RootCid != nil {
-			fmt.Println(*output.RootCid) //nolint:forbidigo
+			_, err = fmt.Fprintln(w, *output.RootCid)
+		}
+	case cfg.OutputGrouped:
+		var groupedOutput string
+
+		if groupedOutput, err = marshalGrouped(output, true); err == nil {
+			_, err = fmt.Fprintln(w, groupedOutput)
+		}
+	case cfg.OutputSarif:
+		var sarifOutput string
+
+		if sarifOutput, err = marshalSarif(output, true); err == nil {
+			_, err = fmt.Fprintln(w, sarifOutput)
 		}
+	case cfg.OutputNdjson:
+		err = writeNdjson(w, output.Artifacts)
+	case cfg.OutputCsv:
+		err = writeTable(w, output.Artifacts, ',')
+	case cfg.OutputTsv:
+		err = writeTable(w, output.Artifacts, '\t')
+	case cfg.OutputJunit:
+		var junitOutput string
+
+		if junitOutput, err = marshalJunit(output); err == nil {
+			_, err = fmt.Fprintln(w, junitOutput)
+		}
+	case cfg.OutputTemplate:
+		err = writeTemplate(w, output, cidList)
 	case cfg.OutputSummary:
 	default:
 		return fmt.Errorf("%w: %s", ErrInvalidOutput, outputMode)
 	}
 
-	return nil
+	return err
+}
+
+// WriteArtifacts writes the `artifacts` output to `w` as pretty-printed JSON,
+// with the artifacts in the configured order.
+func WriteArtifacts(w io.Writer, output Output) error {
+	output.Artifacts = sortArtifacts(output.Artifacts)
+
+	artifactOutput, err := marshalArtifact(output, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, artifactOutput)
+
+	return err
 }
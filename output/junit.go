@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const (
+	junitSuiteName  = "artifact-action"
+	prettyXMLIndent = "  "
+)
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitFailures returns the failure for each invalid artifact file, keyed by
+// its path.
+func junitFailures(invalid []error) map[string]*junitFailure {
+	failures := make(map[string]*junitFailure, len(invalid))
+
+	for _, invalidErr := range invalid {
+		var (
+			parseErr   parse.ArtifactParseError
+			invalidArt parse.InvalidArtifactError
+		)
+
+		switch {
+		case errors.As(invalidErr, &invalidArt):
+			messages := make([]string, len(invalidArt.Reasons))
+			rules := make([]string, 0, len(invalidArt.Reasons))
+			seenRules := make(map[string]struct{}, len(invalidArt.Reasons))
+
+			for i, reason := range invalidArt.Reasons {
+				messages[i] = fmt.Sprintf("%s %s", reason.Field.Literal(), reason.Reason)
+
+				if _, seen := seenRules[reason.Rule]; !seen {
+					seenRules[reason.Rule] = struct{}{}
+					rules = append(rules, reason.Rule)
+				}
+			}
+
+			failures[invalidArt.FilePath] = &junitFailure{
+				Message: strings.Join(messages, "; "),
+				Type:    strings.Join(rules, ","),
+				Text:    strings.Join(messages, "\n"),
+			}
+		case errors.As(invalidErr, &parseErr):
+			failures[parseErr.Path] = &junitFailure{
+				Message: parseErr.Reason,
+				Type:    parse.RuleParse,
+				Text:    parseErr.Reason,
+			}
+		}
+	}
+
+	return failures
+}
+
+// marshalJunit renders the result of validating each artifact file as a JUnit
+// XML report, with a test case for each artifact file.
+func marshalJunit(output Output) (string, error) {
+	failures := junitFailures(output.Invalid)
+
+	paths := make(map[string]struct{}, len(output.Artifacts)+len(failures))
+
+	for _, artifact := range output.Artifacts {
+		paths[artifact.Path] = struct{}{}
+	}
+
+	for path := range failures {
+		paths[path] = struct{}{}
+	}
+
+	sortedPaths := make([]string, 0, len(paths))
+
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+
+	sort.Strings(sortedPaths)
+
+	suite := junitTestSuite{
+		Name:      "validate",
+		Tests:     len(sortedPaths),
+		Failures:  len(failures),
+		Errors:    0,
+		TestCases: make([]junitTestCase, len(sortedPaths)),
+	}
+
+	for i, path := range sortedPaths {
+		suite.TestCases[i] = junitTestCase{
+			Name:      path,
+			ClassName: junitSuiteName,
+			Failure:   failures[path],
+		}
+	}
+
+	report := junitTestSuites{
+		Name:     junitSuiteName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	marshalledOutput, err := xml.MarshalIndent(report, "", prettyXMLIndent)
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(marshalledOutput), nil
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func TestMarshalJunit(t *testing.T) {
+	marshalled, err := marshalJunit(invalidTestOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(marshalled, xml.Header) {
+		t.Errorf("marshalJunit() doesn't start with an XML header")
+	}
+
+	var report junitTestSuites
+
+	if err := xml.Unmarshal([]byte(marshalled), &report); err != nil {
+		t.Fatalf("marshalJunit() is not valid XML: %v", err)
+	}
+
+	if report.Tests != 3 || report.Failures != 2 || report.Errors != 0 {
+		t.Errorf("report counts = %d tests, %d failures, %d errors, want 3, 2, 0", report.Tests, report.Failures, report.Errors)
+	}
+
+	if len(report.Suites) != 1 {
+		t.Fatalf("got %d test suites, want 1", len(report.Suites))
+	}
+
+	suite := report.Suites[0]
+
+	if suite.Tests != 3 || suite.Failures != 2 {
+		t.Errorf("suite counts = %d tests, %d failures, want 3, 2", suite.Tests, suite.Failures)
+	}
+
+	want := []junitTestCase{
+		{
+			Name:      "artifacts/broken.md",
+			ClassName: junitSuiteName,
+			Failure: &junitFailure{
+				Message: "`title` can not be empty; `decades[1]` is not a decade; `decades` is not in chronological order",
+				Type:    "title,decades",
+				Text:    "`title` can not be empty\n`decades[1]` is not a decade\n`decades` is not in chronological order",
+			},
+		},
+		{
+			Name:      "artifacts/unparseable.md",
+			ClassName: junitSuiteName,
+			Failure:   &junitFailure{Message: "no front matter", Type: parse.RuleParse, Text: "no front matter"},
+		},
+		{
+			Name:      "artifacts/valid.md",
+			ClassName: junitSuiteName,
+			Failure:   nil,
+		},
+	}
+
+	if !reflect.DeepEqual(suite.TestCases, want) {
+		t.Errorf("test cases = %+v, want %+v", suite.TestCases, want)
+	}
+}
+
+func TestMarshalJunitWithNoArtifacts(t *testing.T) {
+	marshalled, err := marshalJunit(Output{Artifacts: nil, RootCid: nil, Invalid: nil, PinStats: nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+
+	if err := xml.Unmarshal([]byte(marshalled), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Tests != 0 || report.Failures != 0 || len(report.Suites) != 1 || len(report.Suites[0].TestCases) != 0 {
+		t.Errorf("marshalJunit() = %+v, want an empty suite", report)
+	}
+}
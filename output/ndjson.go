@@ -0,0 +1,35 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// writeNdjson writes each artifact to `w` as a single line of JSON.
+func writeNdjson(w io.Writer, artifacts []parse.Artifact) error {
+	encoder := json.NewEncoder(w)
+
+	for _, artifact := range artifacts {
+		if err := encoder.Encode(artifact); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stream prints artifacts as newline-delimited JSON as soon as they're parsed
+// instead of building the whole output in memory first. It calls `walk` with
+// a function that prints a single artifact, which `walk` should call with
+// each artifact as it's parsed.
+func Stream(walk func(printArtifact func(parse.Artifact) error) error) error {
+	return withOutputWriter(func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+
+		return walk(func(artifact parse.Artifact) error {
+			return encoder.Encode(artifact)
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func ndjsonArtifacts() []parse.Artifact {
+	return []parse.Artifact{
+		{Slug: "first", Entry: parse.GenericEntry{"description": "A description\nwith a newline"}},
+		{Slug: "second", Entry: parse.GenericEntry{"description": "Another description"}},
+	}
+}
+
+// ndjsonSlugs returns the slug of the artifact on each line of `output`,
+// failing if any line isn't a single JSON object.
+func ndjsonSlugs(t *testing.T, output []byte) []string {
+	t.Helper()
+
+	var slugs []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var artifact parse.Artifact
+
+		if err := json.Unmarshal(scanner.Bytes(), &artifact); err != nil {
+			t.Fatalf("line %q isn't a JSON object: %v", scanner.Text(), err)
+		}
+
+		slugs = append(slugs, artifact.Slug)
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return slugs
+}
+
+func TestWriteNdjson(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := writeNdjson(&buf, ndjsonArtifacts()); err != nil {
+		t.Fatal(err)
+	}
+
+	if slugs := ndjsonSlugs(t, buf.Bytes()); strings.Join(slugs, ",") != "first,second" {
+		t.Errorf("writeNdjson() wrote %v, want one line per artifact", slugs)
+	}
+}
+
+func TestStream(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "artifacts.ndjson")
+	setConfig(t, "output-file", outputFile)
+
+	err := Stream(func(printArtifact func(parse.Artifact) error) error {
+		for i, artifact := range ndjsonArtifacts() {
+			if err := printArtifact(artifact); err != nil {
+				return err
+			}
+
+			// Each artifact is written as soon as it's printed, before the
+			// next one is parsed.
+			written, err := os.ReadFile(outputFile)
+			if err != nil {
+				return err
+			}
+
+			if lines := ndjsonSlugs(t, written); len(lines) != i+1 {
+				t.Errorf("after printing %d artifacts, the output has %d lines", i+1, len(lines))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
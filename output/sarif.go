@@ -0,0 +1,191 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const (
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName       = "artifact-action"
+	sarifToolURI        = "https://github.com/acearchive/artifact-action"
+	sarifLevelError     = "error"
+	sarifSourceRootBase = "%SRCROOT%"
+)
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+func sarifRules() ([]sarifRule, map[string]int) {
+	validators := parse.Validators()
+
+	rules := make([]sarifRule, 0, len(validators)+1)
+	ruleIndices := make(map[string]int, len(validators)+1)
+
+	addRule := func(id, description string) {
+		ruleIndices[id] = len(rules)
+		rules = append(rules, sarifRule{
+			ID:                   id,
+			ShortDescription:     sarifMessage{Text: description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevelError},
+		})
+	}
+
+	addRule(parse.RuleParse, "Artifact files must have front matter which matches the schema.")
+
+	for _, validator := range validators {
+		addRule(validator.Rule, validator.Description)
+	}
+
+	return rules, ruleIndices
+}
+
+func newSarifResult(ruleIndices map[string]int, rule, path string, line int, message string) sarifResult {
+	// Code scanning needs a line to attach the result to, so we fall back to
+	// the top of the file.
+	if line <= 0 {
+		line = 1
+	}
+
+	return sarifResult{
+		RuleID:    rule,
+		RuleIndex: ruleIndices[rule],
+		Level:     sarifLevelError,
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{
+						URI:       path,
+						URIBaseID: sarifSourceRootBase,
+					},
+					Region: sarifRegion{StartLine: line},
+				},
+			},
+		},
+	}
+}
+
+// marshalSarif renders the validation failures in `output` as a SARIF 2.1.0
+// log so they can be uploaded to GitHub code scanning.
+func marshalSarif(output Output, pretty bool) (string, error) {
+	rules, ruleIndices := sarifRules()
+
+	results := make([]sarifResult, 0, len(output.Invalid))
+
+	for _, invalidErr := range output.Invalid {
+		var (
+			parseErr   parse.ArtifactParseError
+			invalidArt parse.InvalidArtifactError
+		)
+
+		switch {
+		case errors.As(invalidErr, &invalidArt):
+			for _, reason := range invalidArt.Reasons {
+				message := fmt.Sprintf("%s %s", reason.Field.Literal(), reason.Reason)
+				results = append(results, newSarifResult(ruleIndices, reason.Rule, invalidArt.FilePath, reason.Line, message))
+			}
+		case errors.As(invalidErr, &parseErr):
+			results = append(results, newSarifResult(ruleIndices, parse.RuleParse, parseErr.Path, 0, parseErr.Reason))
+		default:
+			results = append(results, newSarifResult(ruleIndices, parse.RuleParse, "", 0, strings.TrimSpace(invalidErr.Error())))
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           sarifToolName,
+						InformationURI: sarifToolURI,
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	var (
+		marshalledOutput []byte
+		err              error
+	)
+
+	if pretty {
+		marshalledOutput, err = json.MarshalIndent(log, "", prettyJSONIndent)
+	} else {
+		marshalledOutput, err = json.Marshal(log)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(marshalledOutput), nil
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// invalidTestOutput returns an output with a valid artifact file, an artifact
+// file which fails validation, and an artifact file which can't be parsed.
+func invalidTestOutput() Output {
+	return Output{
+		Artifacts: []parse.Artifact{{Path: "artifacts/valid.md", Slug: "valid"}},
+		RootCid:   nil,
+		Invalid: []error{
+			parse.InvalidArtifactError{
+				FilePath: "artifacts/broken.md",
+				Reasons: []parse.InvalidArtifactReason{
+					{Rule: "title", Field: parse.FieldTitle, Reason: "can not be empty", Line: 3},
+					{Rule: "decades", Field: parse.FieldDecades.At(1), Reason: "is not a decade", Line: 9},
+					{Rule: "decades", Field: parse.FieldDecades, Reason: "is not in chronological order", Line: 0},
+				},
+			},
+			parse.ArtifactParseError{Path: "artifacts/unparseable.md", Reason: "no front matter"},
+		},
+		PinStats: nil,
+	}
+}
+
+func TestMarshalSarif(t *testing.T) {
+	for _, pretty := range []bool{false, true} {
+		marshalled, err := marshalSarif(invalidTestOutput(), pretty)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var log sarifLog
+
+		if err := json.Unmarshal([]byte(marshalled), &log); err != nil {
+			t.Fatalf("marshalSarif() is not valid JSON: %v", err)
+		}
+
+		if log.Version != sarifVersion || len(log.Runs) != 1 {
+			t.Fatalf("marshalSarif() = version %q with %d runs", log.Version, len(log.Runs))
+		}
+
+		run := log.Runs[0]
+		rules := run.Tool.Driver.Rules
+
+		// There's a rule for each validator, after the rule for parse errors.
+		if want := len(parse.Validators()) + 1; len(rules) != want {
+			t.Errorf("got %d rules, want %d", len(rules), want)
+		}
+
+		type result struct {
+			rule    string
+			path    string
+			line    int
+			message string
+		}
+
+		want := []result{
+			{"title", "artifacts/broken.md", 3, "`title` can not be empty"},
+			{"decades", "artifacts/broken.md", 9, "`decades[1]` is not a decade"},
+			// Results without a line are attached to the top of the file.
+			{"decades", "artifacts/broken.md", 1, "`decades` is not in chronological order"},
+			{parse.RuleParse, "artifacts/unparseable.md", 1, "no front matter"},
+		}
+
+		got := make([]result, len(run.Results))
+
+		for i, sarifResult := range run.Results {
+			if len(sarifResult.Locations) != 1 {
+				t.Fatalf("result %d has %d locations, want 1", i, len(sarifResult.Locations))
+			}
+
+			location := sarifResult.Locations[0].PhysicalLocation
+
+			got[i] = result{
+				rule:    sarifResult.RuleID,
+				path:    location.ArtifactLocation.URI,
+				line:    location.Region.StartLine,
+				message: sarifResult.Message.Text,
+			}
+
+			if rules[sarifResult.RuleIndex].ID != sarifResult.RuleID {
+				t.Errorf("result %d has rule index %d, which is rule %q, not %q", i, sarifResult.RuleIndex, rules[sarifResult.RuleIndex].ID, sarifResult.RuleID)
+			}
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("marshalSarif(pretty=%t) results = %+v, want %+v", pretty, got, want)
+		}
+	}
+}
+
+func TestMarshalSarifWithUnknownError(t *testing.T) {
+	output := Output{Artifacts: nil, RootCid: nil, Invalid: []error{errors.New("something broke\n")}, PinStats: nil}
+
+	marshalled, err := marshalSarif(output, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+
+	if err := json.Unmarshal([]byte(marshalled), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	results := log.Runs[0].Results
+
+	if len(results) != 1 || results[0].RuleID != parse.RuleParse || results[0].Message.Text != "something broke" {
+		t.Errorf("marshalSarif() results = %+v", results)
+	}
+}
@@ -0,0 +1,122 @@
+package output
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+func commitOf(artifact parse.Artifact) (time.Time, string) {
+	if artifact.Commit == nil {
+		return time.Time{}, ""
+	}
+
+	return artifact.Commit.Date, artifact.Commit.Rev
+}
+
+// compareArtifacts returns a negative number, zero, or a positive number
+// depending on whether `a` sorts before, the same as, or after `b` in the
+// given order. Ties are broken by slug, then commit date, then rev, so the
+// order is the same from run to run.
+func compareArtifacts(order cfg.SortOrder, a, b parse.Artifact) int {
+	aDate, aRev := commitOf(a)
+	bDate, bRev := commitOf(b)
+
+	compareSlug := func() int {
+		switch {
+		case a.Slug < b.Slug:
+			return -1
+		case a.Slug > b.Slug:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	compareDate := func() int {
+		switch {
+		case aDate.Before(bDate):
+			return -1
+		case aDate.After(bDate):
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	compareSortKey := func() int {
+		return strings.Compare(a.SortKey, b.SortKey)
+	}
+
+	compareRev := func() int {
+		switch {
+		case aRev < bRev:
+			return -1
+		case aRev > bRev:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	var comparisons []func() int
+
+	switch order {
+	case cfg.SortDate:
+		comparisons = []func() int{compareDate, compareSlug, compareRev}
+	case cfg.SortNewest:
+		comparisons = []func() int{func() int { return -compareDate() }, compareSlug, compareRev}
+	case cfg.SortTitle:
+		comparisons = []func() int{compareSortKey, compareSlug, compareDate, compareRev}
+	default:
+		comparisons = []func() int{compareSlug, compareDate, compareRev}
+	}
+
+	for _, comparison := range comparisons {
+		if result := comparison(); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// sortArtifacts returns a copy of `artifacts` in the configured order. It
+// doesn't modify `artifacts`.
+func sortArtifacts(artifacts []parse.Artifact) []parse.Artifact {
+	order := cfg.Sort()
+
+	if order == cfg.SortNone || artifacts == nil {
+		return artifacts
+	}
+
+	sorted := make([]parse.Artifact, len(artifacts))
+	copy(sorted, artifacts)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareArtifacts(order, sorted[i], sorted[j]) < 0
+	})
+
+	return sorted
+}
+
+// sortCids returns a copy of `cidList` sorted by the string form of each CID.
+// It doesn't modify `cidList`.
+func sortCids(cidList []cid.Cid) []cid.Cid {
+	if cfg.Sort() == cfg.SortNone || cidList == nil {
+		return cidList
+	}
+
+	sorted := make([]cid.Cid, len(cidList))
+	copy(sorted, cidList)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	return sorted
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+func sortTestArtifact(slug, sortKey string, day int, rev string) parse.Artifact {
+	return parse.Artifact{
+		Slug:    slug,
+		SortKey: sortKey,
+		Commit:  &parse.ArtifactCommit{Rev: rev, Date: time.Date(2022, time.January, day, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+// versionsOf returns the slug and rev of each artifact.
+func versionsOf(artifacts []parse.Artifact) []string {
+	versions := make([]string, len(artifacts))
+
+	for i, artifact := range artifacts {
+		versions[i] = artifact.Slug + "@" + artifact.Commit.Rev
+	}
+
+	return versions
+}
+
+func TestSortArtifacts(t *testing.T) {
+	artifacts := []parse.Artifact{
+		sortTestArtifact("zine", "zine about aces", 3, "c"),
+		sortTestArtifact("manifesto", "asexual manifesto", 2, "b"),
+		sortTestArtifact("zine", "zine about aces", 1, "a"),
+		// Versions with the same date are ordered by rev.
+		sortTestArtifact("manifesto", "asexual manifesto", 2, "a"),
+		// The same sort key as `zine`, so it's ordered by slug.
+		sortTestArtifact("another-zine", "zine about aces", 4, "d"),
+	}
+
+	original := versionsOf(artifacts)
+
+	tests := []struct {
+		order cfg.SortOrder
+		want  []string
+	}{
+		{cfg.SortSlug, []string{"another-zine@d", "manifesto@a", "manifesto@b", "zine@a", "zine@c"}},
+		{cfg.SortDate, []string{"zine@a", "manifesto@a", "manifesto@b", "zine@c", "another-zine@d"}},
+		{cfg.SortNewest, []string{"another-zine@d", "zine@c", "manifesto@a", "manifesto@b", "zine@a"}},
+		{cfg.SortTitle, []string{"manifesto@a", "manifesto@b", "another-zine@d", "zine@a", "zine@c"}},
+		{cfg.SortNone, original},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.order), func(t *testing.T) {
+			setConfig(t, "sort", string(test.order))
+
+			if got := versionsOf(sortArtifacts(artifacts)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("sortArtifacts() = %v, want %v", got, test.want)
+			}
+
+			if got := versionsOf(artifacts); !reflect.DeepEqual(got, original) {
+				t.Errorf("sortArtifacts() modified its argument: %v", got)
+			}
+		})
+	}
+}
+
+func TestSortArtifactsWithoutCommits(t *testing.T) {
+	setConfig(t, "sort", string(cfg.SortDate))
+
+	artifacts := []parse.Artifact{
+		sortTestArtifact("b", "", 1, "a"),
+		{Slug: "c", Commit: nil},
+		{Slug: "a", Commit: nil},
+	}
+
+	got := sortArtifacts(artifacts)
+	slugs := []string{got[0].Slug, got[1].Slug, got[2].Slug}
+
+	// Artifacts from the tree have no commit, so they sort before any
+	// artifact from the history.
+	if want := []string{"a", "c", "b"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("sortArtifacts() = %v, want %v", slugs, want)
+	}
+}
+
+func TestDefaultSort(t *testing.T) {
+	tests := []struct {
+		name   string
+		output cfg.OutputType
+		sort   interface{}
+		want   cfg.SortOrder
+	}{
+		{name: "default", output: cfg.OutputArtifacts, sort: nil, want: cfg.DefaultSort},
+		{name: "ndjson", output: cfg.OutputNdjson, sort: nil, want: cfg.DefaultSort},
+		{name: "ndjson unsorted", output: cfg.OutputNdjson, sort: string(cfg.SortNone), want: cfg.SortNone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setConfig(t, "output", string(test.output))
+			setConfig(t, "sort", test.sort)
+
+			if got := cfg.Sort(); got != test.want {
+				t.Errorf("cfg.Sort() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSortCids(t *testing.T) {
+	cidStrs := []string{
+		"bafybeihsf4562gmmyoya7eh5buxv65lqcdoil3wsi5jf5fceskap7yzooi",
+		"bafybeib2fu4qf44xiyduvhadog5raukc3ajdnd4qpsavyxaa2umzjeif5y",
+	}
+
+	cidList := make([]cid.Cid, len(cidStrs))
+
+	for i, cidStr := range cidStrs {
+		id, err := cid.Decode(cidStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cidList[i] = id
+	}
+
+	setConfig(t, "sort", string(cfg.SortSlug))
+
+	sorted := sortCids(cidList)
+
+	if sorted[0] != cidList[1] || sorted[1] != cidList[0] {
+		t.Errorf("sortCids() = %v", sorted)
+	}
+
+	if cidList[0].String() != cidStrs[0] {
+		t.Error("sortCids() modified its argument")
+	}
+
+	setConfig(t, "sort", string(cfg.SortNone))
+
+	if unsorted := sortCids(cidList); !reflect.DeepEqual(unsorted, cidList) {
+		t.Errorf("sortCids() with no order = %v, want %v", unsorted, cidList)
+	}
+}
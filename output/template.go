@@ -0,0 +1,150 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/acearchive/artifact-action/cfg"
+	"github.com/acearchive/artifact-action/gateway"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+// TemplateData is the data that templates are rendered with. It's part of
+// the public interface of the CLI, so fields should only ever be added to it.
+type TemplateData struct {
+	// Artifacts is the artifacts, the same as in the `artifacts` output.
+	Artifacts []parse.Artifact
+
+	// RootCid is the CID of the root directory in `dir` and `pin` mode, and
+	// nil otherwise.
+	RootCid *string
+
+	// Cids is the CID of every file in `Artifacts`, in the same form as the
+	// `cids` output.
+	Cids []string
+}
+
+func cidString(value interface{}) (string, error) {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue, nil
+	case cid.Cid:
+		return typedValue.String(), nil
+	case *string:
+		if typedValue == nil {
+			return "", nil
+		}
+
+		return *typedValue, nil
+	default:
+		return "", fmt.Errorf("not a CID: %v", value)
+	}
+}
+
+func formatDate(layout string, value interface{}) (string, error) {
+	switch typedValue := value.(type) {
+	case time.Time:
+		return typedValue.Format(layout), nil
+	case *time.Time:
+		if typedValue == nil {
+			return "", nil
+		}
+
+		return typedValue.Format(layout), nil
+	default:
+		return "", fmt.Errorf("not a date: %v", value)
+	}
+}
+
+func marshalJSON(value interface{}, pretty bool) (string, error) {
+	var (
+		marshalledValue []byte
+		err             error
+	)
+
+	if pretty {
+		marshalledValue, err = json.MarshalIndent(value, "", prettyJSONIndent)
+	} else {
+		marshalledValue, err = json.Marshal(value)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(marshalledValue), nil
+}
+
+var templateFuncs = template.FuncMap{
+	"cidv1": func(value interface{}) (string, error) {
+		id, err := cidString(value)
+		if err != nil {
+			return "", err
+		}
+
+		return gateway.CidV1(id)
+	},
+	"gatewayURL": func(value interface{}, filename ...string) (string, error) {
+		id, err := cidString(value)
+		if err != nil {
+			return "", err
+		}
+
+		return gateway.URL(cfg.Gateway(), id, strings.Join(filename, ""))
+	},
+	"ipfsURL": func(value interface{}) (string, error) {
+		id, err := cidString(value)
+		if err != nil {
+			return "", err
+		}
+
+		return gateway.IpfsURL(id)
+	},
+	"json": func(value interface{}) (string, error) {
+		return marshalJSON(value, false)
+	},
+	"prettyJSON": func(value interface{}) (string, error) {
+		return marshalJSON(value, true)
+	},
+	"date": formatDate,
+	"join": func(sep string, values interface{}) (string, error) {
+		switch typedValues := values.(type) {
+		case nil:
+			return "", nil
+		case []string:
+			return strings.Join(typedValues, sep), nil
+		case []interface{}:
+			strs := make([]string, len(typedValues))
+
+			for i, value := range typedValues {
+				strs[i] = fmt.Sprint(value)
+			}
+
+			return strings.Join(strs, sep), nil
+		default:
+			return "", fmt.Errorf("not a list: %v", values)
+		}
+	},
+}
+
+// writeTemplate renders the configured template file with `output` to `w`.
+func writeTemplate(w io.Writer, output Output, cidList []cid.Cid) error {
+	tmpl, err := template.New(filepath.Base(cfg.Template())).Funcs(templateFuncs).ParseFiles(cfg.Template())
+	if err != nil {
+		return err
+	}
+
+	cids := make([]string, len(cidList))
+
+	for i, id := range cidList {
+		cids[i] = id.String()
+	}
+
+	return tmpl.Execute(w, TemplateData{Artifacts: output.Artifacts, RootCid: output.RootCid, Cids: cids})
+}
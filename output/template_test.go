@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+func renderTemplate(t *testing.T, text string, output Output, cidList []cid.Cid) (string, error) {
+	t.Helper()
+
+	templatePath := filepath.Join(t.TempDir(), "test.tmpl")
+
+	if err := os.WriteFile(templatePath, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	setConfig(t, "template", templatePath)
+
+	var buf bytes.Buffer
+
+	err := writeTemplate(&buf, output, cidList)
+
+	return buf.String(), err
+}
+
+func TestWriteTemplate(t *testing.T) {
+	rootCid := "bafybeihsf4562gmmyoya7eh5buxv65lqcdoil3wsi5jf5fceskap7yzooi"
+
+	fileCid, err := cid.Decode("bafybeib2fu4qf44xiyduvhadog5raukc3ajdnd4qpsavyxaa2umzjeif5y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := Output{
+		Artifacts: []parse.Artifact{{Slug: "first"}, {Slug: "second"}},
+		RootCid:   &rootCid,
+		Invalid:   nil,
+		PinStats:  nil,
+	}
+
+	got, err := renderTemplate(t, "{{ range .Artifacts }}{{ .Slug }} {{ end }}{{ cidv1 .RootCid }} {{ join \",\" .Cids }}", output, []cid.Cid{fileCid})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "first second " + rootCid + " " + fileCid.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateDataHidesInternalFields(t *testing.T) {
+	for _, field := range []string{"Invalid", "PinStats"} {
+		_, err := renderTemplate(t, "{{ ."+field+" }}", Output{Artifacts: nil, RootCid: nil, Invalid: nil, PinStats: nil}, nil)
+
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("rendering .%s: got error %v, want an error about the field", field, err)
+		}
+	}
+}
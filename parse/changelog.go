@@ -0,0 +1,219 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ChangeKind is how an artifact changed between two commits.
+type ChangeKind string
+
+const (
+	// ChangeNew is an artifact which was added.
+	ChangeNew ChangeKind = "new"
+
+	// ChangeNewFiles is an artifact which had files added to it.
+	ChangeNewFiles ChangeKind = "new-files"
+
+	// ChangeCorrection is an artifact which was changed in any other way.
+	ChangeCorrection ChangeKind = "correction"
+
+	// ChangeRemoved is an artifact which was removed.
+	ChangeRemoved ChangeKind = "removed"
+)
+
+// Change is how an artifact changed between two commits.
+type Change struct {
+	Kind  ChangeKind
+	Slug  string
+	Title string
+
+	// NewFiles is the filenames of the files which were added, for
+	// `ChangeNewFiles`.
+	NewFiles []string
+
+	// Subjects is the subject of each commit which changed the artifact, from
+	// least to most recent.
+	Subjects []string
+}
+
+// artifactAt is the state of an artifact file as of a commit.
+type artifactAt struct {
+	exists   bool
+	contents string
+	entry    GenericEntry
+}
+
+func readArtifactAt(commit *object.Commit, path string) (artifactAt, error) {
+	file, err := commit.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return artifactAt{exists: false, contents: "", entry: nil}, nil
+	} else if err != nil {
+		return artifactAt{}, err
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return artifactAt{}, err
+	}
+
+	state := artifactAt{exists: true, contents: contents, entry: GenericEntry{}}
+
+	// An artifact file which can't be parsed is still compared by its
+	// contents.
+	if frontMatter, _, err := extractFrontMatter(io.NopCloser(strings.NewReader(contents))); err == nil {
+		if entry, err := parseGenericEntry(frontMatter); err == nil {
+			state.entry = entry
+		}
+	}
+
+	return state, nil
+}
+
+func entryFilenames(entry GenericEntry) map[string]struct{} {
+	filenames := make(map[string]struct{})
+
+	for _, file := range entry.Objects(FieldFiles) {
+		if filename := strings.TrimSpace(file.String(FieldFileFilename)); filename != "" {
+			filenames[filename] = struct{}{}
+		}
+	}
+
+	return filenames
+}
+
+// classify returns how an artifact changed from `before` to `after`, and
+// whether it changed at all.
+func classify(before, after artifactAt) (ChangeKind, []string, bool) {
+	switch {
+	case !before.exists && !after.exists:
+		return "", nil, false
+	case !before.exists:
+		return ChangeNew, nil, true
+	case !after.exists:
+		return ChangeRemoved, nil, true
+	case before.contents == after.contents:
+		return "", nil, false
+	}
+
+	beforeFilenames := entryFilenames(before.entry)
+
+	var newFiles []string
+
+	for filename := range entryFilenames(after.entry) {
+		if _, exists := beforeFilenames[filename]; !exists {
+			newFiles = append(newFiles, filename)
+		}
+	}
+
+	if len(newFiles) > 0 {
+		sort.Strings(newFiles)
+		return ChangeNewFiles, newFiles, true
+	}
+
+	return ChangeCorrection, nil, true
+}
+
+// Changelog returns how each artifact changed between the commits `from` and
+// `to`, which may be branches, tags, or commit hashes. If `to` is empty, it's
+// `HEAD`. Changes are sorted by slug.
+func Changelog(ctx context.Context, workspacePath, artifactsPath, from, to string) ([]Change, error) {
+	repo, err := git.PlainOpen(workspacePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == "" {
+		to = "HEAD"
+	}
+
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := findRevisions(ctx, workspacePath, artifactsPath, revisionRange{from: from, to: to})
+	if err != nil {
+		return nil, err
+	}
+
+	// The revisions are from most to least recent, but we want the subjects
+	// from least to most recent.
+	subjects := make(map[string][]string)
+	paths := make([]string, 0)
+
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := revisions[i]
+
+		if _, exists := subjects[revision.Path]; !exists {
+			paths = append(paths, revision.Path)
+		}
+
+		subjects[revision.Path] = append(subjects[revision.Path], revision.Subject)
+	}
+
+	changes := make([]Change, 0, len(paths))
+
+	for _, path := range paths {
+		before, err := readArtifactAt(fromCommit, path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		after, err := readArtifactAt(toCommit, path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		kind, newFiles, changed := classify(before, after)
+		if !changed {
+			continue
+		}
+
+		title := after.entry.String(FieldTitle)
+		if !after.exists {
+			title = before.entry.String(FieldTitle)
+		}
+
+		changes = append(changes, Change{
+			Kind:     kind,
+			Slug:     strings.TrimSuffix(filepath.Base(path), ArtifactFileExtension),
+			Title:    title,
+			NewFiles: newFiles,
+			Subjects: uniqueStrings(subjects[path]),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Slug < changes[j].Slug
+	})
+
+	return changes, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if _, exists := seen[value]; !exists {
+			seen[value] = struct{}{}
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
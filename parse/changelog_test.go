@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// changelogArtifact returns the contents of an artifact file with the given
+// title and filenames.
+func changelogArtifact(title string, filenames ...string) string {
+	contents := strings.Replace(linkOnlyArtifact, `"The second"`, `"`+title+`"`, 1)
+
+	if len(filenames) == 0 {
+		return contents
+	}
+
+	files := "files:\n"
+	for _, filename := range filenames {
+		files += "  - name: \"" + filename + "\"\n    filename: \"" + filename + "\"\n"
+	}
+
+	return strings.Replace(contents, "people: []\n", files+"people: []\n", 1)
+}
+
+func TestChangelog(t *testing.T) {
+	repo := newTestRepo(t)
+
+	from := repo.commit(map[string]string{
+		"artifacts/edited.md":      changelogArtifact("Edited"),
+		"artifacts/new-files.md":   changelogArtifact("New files", "scan.pdf"),
+		"artifacts/old-name.md":    changelogArtifact("Renamed"),
+		"artifacts/deleted.md":     changelogArtifact("Deleted"),
+		"artifacts/unchanged.md":   changelogArtifact("Unchanged"),
+		"artifacts/edited-back.md": changelogArtifact("Edited back"),
+	})
+
+	repo.commit(map[string]string{
+		"artifacts/added.md":       changelogArtifact("Added"),
+		"artifacts/edited.md":      changelogArtifact("Edited again"),
+		"artifacts/edited-back.md": changelogArtifact("Edited forth"),
+	})
+
+	repo.commit(map[string]string{
+		"artifacts/new-files.md":   changelogArtifact("New files", "transcript.html", "scan.pdf", "audio.mp3"),
+		"artifacts/new-name.md":    changelogArtifact("Renamed"),
+		"artifacts/edited-back.md": changelogArtifact("Edited back"),
+	}, "artifacts/old-name.md", "artifacts/deleted.md")
+
+	changes, err := Changelog(context.Background(), repo.dir, artifactsPath, from.String(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subjects := []string{"Commit"}
+
+	// An artifact which was renamed is removed under its old slug and added
+	// under its new one, and an artifact which was changed and then changed
+	// back isn't in the changelog.
+	want := []Change{
+		{Kind: ChangeNew, Slug: "added", Title: "Added", NewFiles: nil, Subjects: subjects},
+		{Kind: ChangeRemoved, Slug: "deleted", Title: "Deleted", NewFiles: nil, Subjects: subjects},
+		{Kind: ChangeCorrection, Slug: "edited", Title: "Edited again", NewFiles: nil, Subjects: subjects},
+		{Kind: ChangeNewFiles, Slug: "new-files", Title: "New files", NewFiles: []string{"audio.mp3", "transcript.html"}, Subjects: subjects},
+		{Kind: ChangeNew, Slug: "new-name", Title: "Renamed", NewFiles: nil, Subjects: subjects},
+		{Kind: ChangeRemoved, Slug: "old-name", Title: "Renamed", NewFiles: nil, Subjects: subjects},
+	}
+
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changelog() =\n%+v\nwant\n%+v", changes, want)
+	}
+}
+
+func TestChangelogToRevision(t *testing.T) {
+	repo := newTestRepo(t)
+
+	from := repo.commit(map[string]string{"artifacts/first.md": changelogArtifact("First")})
+	to := repo.commit(map[string]string{"artifacts/second.md": changelogArtifact("Second")})
+	repo.commit(map[string]string{"artifacts/third.md": changelogArtifact("Third")})
+
+	changes, err := Changelog(context.Background(), repo.dir, artifactsPath, from.String(), to.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].Slug != "second" || changes[0].Kind != ChangeNew {
+		t.Errorf("Changelog() = %+v, want only the artifact added by the second commit", changes)
+	}
+}
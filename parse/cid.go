@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"fmt"
+
 	"github.com/acearchive/artifact-action/logger"
 	"github.com/ipfs/go-cid"
 )
@@ -21,12 +23,13 @@ func (a Artifact) listCids() []cid.Cid {
 
 	a.Entry.ToTyped(&entry)
 
-	cidList := make([]cid.Cid, len(entry.Files))
+	cidList := make([]cid.Cid, 0, len(entry.Files))
 
-	for fileIndex, artifactFile := range entry.Files {
+	// Past versions of an artifact file may have invalid CIDs, which we skip.
+	for _, artifactFile := range entry.Files {
 		artifactCid, err := cid.Parse(artifactFile.Cid)
 		if err == nil {
-			cidList[fileIndex] = artifactCid
+			cidList = append(cidList, artifactCid)
 		}
 	}
 
@@ -47,7 +50,7 @@ func ExtractCids(artifacts []Artifact) ([]cid.Cid, error) {
 		}
 	}
 
-	logger.Printf("Found %d unique CIDs in artifact files\n", len(cidList))
+	logger.LogInfo(fmt.Sprintf("Found %d unique CIDs in artifact files", len(cidList)), logger.Phase("cids"))
 
 	return cidList, nil
 }
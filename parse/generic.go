@@ -0,0 +1,107 @@
+package parse
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The accessors in this file read fields from a `GenericEntry` without
+// assuming which schema version it follows. A field which is missing or has
+// an unexpected type is treated as empty rather than as an error, because
+// past schema versions may not have had it or may have had a different type
+// for it.
+
+func stringOf(value interface{}) (string, bool) {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue, true
+	case int, int64, bool:
+		return fmt.Sprint(typedValue), true
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+func intOf(value interface{}) (int, bool) {
+	switch typedValue := value.(type) {
+	case int:
+		return typedValue, true
+	case int64:
+		return int(typedValue), true
+	case float64:
+		if typedValue != math.Trunc(typedValue) {
+			return 0, false
+		}
+
+		return int(typedValue), true
+	case string:
+		parsedValue, err := strconv.Atoi(strings.TrimSpace(typedValue))
+		return parsedValue, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// String returns the value of a string field, or an empty string if it's
+// missing.
+func (e GenericEntry) String(field EntryField) string {
+	value, _ := stringOf(e[string(field)])
+	return value
+}
+
+// Int returns the value of an integer field, and whether it's present.
+func (e GenericEntry) Int(field EntryField) (int, bool) {
+	return intOf(e[string(field)])
+}
+
+// Strings returns the values of a list of strings, skipping any values which
+// aren't strings.
+func (e GenericEntry) Strings(field EntryField) []string {
+	values, _ := e[string(field)].([]interface{})
+	strs := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if str, ok := stringOf(value); ok {
+			strs = append(strs, str)
+		}
+	}
+
+	return strs
+}
+
+// Ints returns the values of a list of integers, skipping any values which
+// aren't integers.
+func (e GenericEntry) Ints(field EntryField) []int {
+	values, _ := e[string(field)].([]interface{})
+	ints := make([]int, 0, len(values))
+
+	for _, value := range values {
+		if i, ok := intOf(value); ok {
+			ints = append(ints, i)
+		}
+	}
+
+	return ints
+}
+
+// Objects returns the values of a list of objects, like `files` or `links`,
+// skipping any values which aren't objects.
+func (e GenericEntry) Objects(field EntryField) []GenericEntry {
+	values, _ := e[string(field)].([]interface{})
+	objects := make([]GenericEntry, 0, len(values))
+
+	for _, value := range values {
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			objects = append(objects, typedValue)
+		case GenericEntry:
+			objects = append(objects, typedValue)
+		}
+	}
+
+	return objects
+}
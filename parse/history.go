@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -8,106 +10,407 @@ import (
 
 	"github.com/acearchive/artifact-action/logger"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
+// Revision is a change to an artifact file in a commit.
 type Revision struct {
-	File object.File
-	Path string
-	Rev  string
-	Date time.Time
+	// File is the artifact file as of the commit, or nil if the commit
+	// deleted it.
+	File    *object.File
+	Path    string
+	Rev     string
+	Date    time.Time
+	Subject string
 }
 
-// findRevisions returns all the commits in the git history reachable from
-// `HEAD` and returns them in order from most to least recent.
-func findRevisions(workspacePath, artifactsPath string) ([]Revision, error) {
-	artifactsGlob := filepath.Join(artifactsPath, fmt.Sprintf("*%s", ArtifactFileExtension))
+// revisionRange is a range of commits to find revisions in. It includes the
+// commits reachable from `to` but not from `from`, like `from..to` in git.
+type revisionRange struct {
+	// from is empty to start from the first commit.
+	from string
 
-	repo, err := git.PlainOpen(workspacePath)
+	// to is empty to end at `HEAD`.
+	to string
+}
+
+// resolveCommit returns the commit that the revision `rev` refers to, which
+// may be a branch, a tag, or a commit hash.
+func resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rev, err)
+	}
+
+	return repo.CommitObject(*hash)
+}
+
+// reachableCommits returns the hash of every commit reachable from `commit`.
+func reachableCommits(ctx context.Context, repo *git.Repository, commit *object.Commit) (map[plumbing.Hash]struct{}, error) {
+	reachable := make(map[plumbing.Hash]struct{})
+
+	commitIter, err := repo.Log(&git.LogOptions{From: commit.Hash})
+	if err != nil {
+		return nil, err
+	}
+
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		reachable[commit.Hash] = struct{}{}
+
+		return nil
+	})
+
+	return reachable, err
+}
+
+// commitSubject returns the first line of the message of `commit`.
+func commitSubject(commit *object.Commit) string {
+	return strings.TrimSpace(strings.SplitN(commit.Message, "\n", 2)[0])
+}
+
+// artifactsPattern returns the glob pattern which matches artifact files in
+// `artifactsPath`.
+func artifactsPattern(artifactsPath string) string {
+	return filepath.Join(artifactsPath, fmt.Sprintf("*%s", ArtifactFileExtension))
+}
+
+// diffParent returns the changes `commit` made relative to its first parent.
+// With `detectRenames`, a renamed file is a single change from the old path to
+// the new path, as in `git diff --find-renames`.
+func diffParent(ctx context.Context, commit *object.Commit, detectRenames bool) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	return object.DiffTreeWithOptions(ctx, parentTree, tree, &object.DiffTreeOptions{DetectRenames: detectRenames})
+}
+
+// changedPaths returns the path of every file which `commit` added, modified,
+// or deleted relative to its first parent. Renames are reported as a deletion
+// of the old path and an addition of the new path.
+func changedPaths(ctx context.Context, commit *object.Commit) ([]string, error) {
+	changes, err := diffParent(ctx, commit, false)
 	if err != nil {
 		return nil, err
 	}
 
+	paths := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths = append(paths, change.From.Name)
+		}
+
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			paths = append(paths, change.To.Name)
+		}
+	}
+
+	return paths, nil
+}
+
+// walkCommits calls `fn` with each commit in `revRange` which changed an
+// artifact file, in order from most to least recent.
+func walkCommits(ctx context.Context, workspacePath, artifactsPath string, revRange revisionRange, fn func(*object.Commit) error) error {
+	artifactsGlob := artifactsPattern(artifactsPath)
+
+	repo, err := git.PlainOpen(workspacePath)
+	if err != nil {
+		return err
+	}
+
 	pathFilter := func(path string) bool {
 		matches, _ := filepath.Match(artifactsGlob, path)
 		return matches
 	}
 
-	commitIter, err := repo.Log(&git.LogOptions{PathFilter: pathFilter, Order: git.LogOrderCommitterTime})
-	if err != nil {
-		return nil, err
+	logOptions := &git.LogOptions{PathFilter: pathFilter, Order: git.LogOrderCommitterTime}
+
+	if revRange.to != "" {
+		toCommit, err := resolveCommit(repo, revRange.to)
+		if err != nil {
+			return err
+		}
+
+		logOptions.From = toCommit.Hash
 	}
 
-	var revs []Revision
+	var excluded map[plumbing.Hash]struct{}
+
+	if revRange.from != "" {
+		fromCommit, err := resolveCommit(repo, revRange.from)
+		if err != nil {
+			return err
+		}
+
+		if excluded, err = reachableCommits(ctx, repo, fromCommit); err != nil {
+			return err
+		}
+	}
+
+	commitIter, err := repo.Log(logOptions)
+	if err != nil {
+		return err
+	}
 
 	commitFunc := func(commit *object.Commit) error {
-		stats, err := commit.Stats()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, isExcluded := excluded[commit.Hash]; isExcluded {
+			return nil
+		}
+
+		return fn(commit)
+	}
+
+	return commitIter.ForEach(commitFunc)
+}
+
+// walkRevisions calls `fn` with each revision of each artifact file in the git
+// history in `revRange`, in order from most to least recent.
+func walkRevisions(ctx context.Context, workspacePath, artifactsPath string, revRange revisionRange, fn func(Revision) error) error {
+	artifactsGlob := artifactsPattern(artifactsPath)
+
+	return walkCommits(ctx, workspacePath, artifactsPath, revRange, func(commit *object.Commit) error {
+		paths, err := changedPaths(ctx, commit)
 		if err != nil {
 			return err
 		}
 
-		for _, stat := range stats {
-			if matches, _ := filepath.Match(artifactsGlob, stat.Name); matches {
-				file, err := commit.File(stat.Name)
-				if err != nil {
+		for _, path := range paths {
+			if matches, _ := filepath.Match(artifactsGlob, path); matches {
+				// The file doesn't exist as of this commit if this commit
+				// deleted it.
+				file, err := commit.File(path)
+				if err != nil && !errors.Is(err, object.ErrFileNotFound) {
 					return err
 				}
 
-				revs = append(revs, Revision{
-					File: *file,
-					Path: stat.Name,
-					Rev:  commit.Hash.String(),
-					Date: commit.Committer.When,
-				})
+				if err := fn(Revision{
+					File:    file,
+					Path:    path,
+					Rev:     commit.Hash.String(),
+					Date:    commit.Committer.When,
+					Subject: commitSubject(commit),
+				}); err != nil {
+					return err
+				}
 			}
 		}
 
 		return nil
-	}
+	})
+}
+
+// slugOfPath returns the slug of the artifact file at `path`.
+func slugOfPath(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ArtifactFileExtension)
+}
+
+// Renames returns the slug each artifact file in the git history was renamed
+// to, keyed by the slug it was renamed from, as detected by git. If a slug was
+// renamed more than once, the most recent rename wins. Renames can chain, so
+// the slug an artifact was renamed to may itself have been renamed since.
+func Renames(ctx context.Context, workspacePath, artifactsPath string) (map[string]string, error) {
+	artifactsGlob := artifactsPattern(artifactsPath)
+	renames := make(map[string]string)
+
+	if err := walkCommits(ctx, workspacePath, artifactsPath, revisionRange{}, func(commit *object.Commit) error {
+		changes, err := diffParent(ctx, commit, true)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if change.From.Name == "" || change.To.Name == "" || change.From.Name == change.To.Name {
+				continue
+			}
+
+			fromMatches, _ := filepath.Match(artifactsGlob, change.From.Name)
+			toMatches, _ := filepath.Match(artifactsGlob, change.To.Name)
+
+			if !fromMatches || !toMatches {
+				continue
+			}
+
+			// Commits are walked from most to least recent.
+			if _, exists := renames[slugOfPath(change.From.Name)]; !exists {
+				renames[slugOfPath(change.From.Name)] = slugOfPath(change.To.Name)
+			}
+		}
 
-	if err := commitIter.ForEach(commitFunc); err != nil {
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
-	return revs, nil
+	return renames, nil
 }
 
-func History(workspacePath, artifactsPath string) ([]Artifact, error) {
-	artifactRevisions, err := findRevisions(workspacePath, artifactsPath)
-	if err != nil {
+// CurrentSlug follows the chain of renames in `renames`, as returned by
+// `Renames`, from `slug` to the slug of an artifact in `current`. It reports
+// whether the chain leads to one, which it doesn't if the artifact was
+// deleted.
+func CurrentSlug(slug string, renames map[string]string, current map[string]struct{}) (string, bool) {
+	seen := make(map[string]struct{})
+
+	for {
+		if _, exists := current[slug]; exists {
+			return slug, true
+		}
+
+		if _, isCycle := seen[slug]; isCycle {
+			return "", false
+		}
+
+		seen[slug] = struct{}{}
+
+		next, isRenamed := renames[slug]
+		if !isRenamed {
+			return "", false
+		}
+
+		slug = next
+	}
+}
+
+// findRevisions returns each revision of each artifact file in the git history
+// in `revRange`, in order from most to least recent.
+func findRevisions(ctx context.Context, workspacePath, artifactsPath string, revRange revisionRange) ([]Revision, error) {
+	var revisions []Revision
+
+	if err := walkRevisions(ctx, workspacePath, artifactsPath, revRange, func(revision Revision) error {
+		revisions = append(revisions, revision)
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
-	artifacts := make([]Artifact, 0, len(artifactRevisions))
+	return revisions, nil
+}
+
+// WalkHistory calls `fn` with every version of every artifact in the git
+// history as soon as it's parsed, in order from most to least recent. This
+// avoids holding the whole history in memory. Versions which are not valid
+// YAML are skipped.
+func WalkHistory(ctx context.Context, workspacePath, artifactsPath string, fn func(Artifact) error) error {
+	startTime := time.Now()
+	artifactCount := 0
+
+	revisionFunc := func(revision Revision) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if revision.File == nil {
+			return nil
+		}
 
-	for revIndex, revision := range artifactRevisions {
 		artifactFile, err := revision.File.Reader()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		frontMatter, err := extractFrontMatter(artifactFile)
+		slug := slugOfPath(revision.File.Name)
+
+		frontMatter, _, err := extractFrontMatter(artifactFile)
 		if err != nil {
-			continue
+			logger.LogDebug(fmt.Sprintf("Skipping revision %s of %s: %s", revision.Rev, revision.Path, err), logger.Slug(slug), logger.Phase("history"))
+			return nil
 		}
 
 		entry, err := parseGenericEntry(frontMatter)
 		if err != nil {
-			continue
+			logger.LogDebug(fmt.Sprintf("Skipping revision %s of %s: %s", revision.Rev, revision.Path, err), logger.Slug(slug), logger.Phase("history"))
+			return nil
 		}
 
-		artifacts = append(artifacts, Artifact{
-			Path: revision.File.Name,
-			Slug: strings.TrimSuffix(filepath.Base(revision.File.Name), ArtifactFileExtension),
-			Commit: &ArtifactCommit{
-				Rev:  artifactRevisions[revIndex].Rev,
-				Date: artifactRevisions[revIndex].Date.UTC(),
-			},
-			Entry: entry,
-		})
+		artifactCount++
+
+		return fn(newArtifact(revision.File.Name, slug, &ArtifactCommit{Rev: revision.Rev, Date: revision.Date.UTC()}, entry))
+	}
+
+	if err := walkRevisions(ctx, workspacePath, artifactsPath, revisionRange{}, revisionFunc); err != nil {
+		return err
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("Found %d artifact files in the history", artifactCount),
+		logger.Phase("history"),
+		logger.Duration(time.Since(startTime)),
+	)
+
+	return nil
+}
+
+// HeadSlugs returns the slug of each artifact file in the tree of the `HEAD`
+// commit, which are the artifacts that currently exist. Artifacts in the
+// history which aren't in it were renamed or deleted.
+func HeadSlugs(workspacePath, artifactsPath string) (map[string]struct{}, error) {
+	artifactsGlob := artifactsPattern(artifactsPath)
+
+	repo, err := git.PlainOpen(workspacePath)
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Printf("Found %d artifact files in the history\n", len(artifacts))
+	head, err := resolveCommit(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make(map[string]struct{})
+
+	if err := tree.Files().ForEach(func(file *object.File) error {
+		if matches, _ := filepath.Match(artifactsGlob, file.Name); matches {
+			slugs[slugOfPath(file.Name)] = struct{}{}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return slugs, nil
+}
+
+// History returns every version of every artifact in the git history, in
+// order from most to least recent.
+func History(ctx context.Context, workspacePath, artifactsPath string) ([]Artifact, error) {
+	var artifacts []Artifact
+
+	if err := WalkHistory(ctx, workspacePath, artifactsPath, func(artifact Artifact) error {
+		artifacts = append(artifacts, artifact)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
 	return artifacts, nil
 }
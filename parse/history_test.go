@@ -0,0 +1,200 @@
+package parse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const artifactsPath = "artifacts/"
+
+const linkOnlyArtifact = `---
+version: 3
+title: "The second"
+description: "An artifact with only links"
+links:
+  - name: "Website"
+    url: "https://example.com/second"
+people: []
+identities: []
+fromYear: 1990
+decades: [1990]
+aliases: []
+---
+`
+
+// testRepo is a git repository for tests to commit artifact files to.
+type testRepo struct {
+	t        *testing.T
+	dir      string
+	worktree *git.Worktree
+	commits  int
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testRepo{t: t, dir: dir, worktree: worktree, commits: 0}
+}
+
+// commit writes the files in `write`, removes the files in `remove`, and
+// commits the result, returning its hash. Each commit is a day after the last.
+func (r *testRepo) commit(write map[string]string, remove ...string) plumbing.Hash {
+	r.t.Helper()
+
+	for name, contents := range write {
+		path := filepath.Join(r.dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+
+	for _, name := range remove {
+		if err := os.Remove(filepath.Join(r.dir, name)); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+
+	if err := r.worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		r.t.Fatal(err)
+	}
+
+	r.commits++
+
+	signature := &object.Signature{
+		Name:  "Test",
+		Email: "test@example.com",
+		When:  time.Date(2022, time.January, r.commits, 0, 0, 0, 0, time.UTC),
+	}
+
+	hash, err := r.worktree.Commit("Commit", &git.CommitOptions{All: true, Author: signature, Committer: signature})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return hash
+}
+
+func TestRenames(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(map[string]string{"artifacts/the-second.md": linkOnlyArtifact})
+	repo.commit(map[string]string{"artifacts/second.md": linkOnlyArtifact}, "artifacts/the-second.md")
+	repo.commit(map[string]string{"artifacts/latest-second.md": linkOnlyArtifact + "\nEdited.\n"}, "artifacts/second.md")
+
+	renames, err := Renames(context.Background(), repo.dir, artifactsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"the-second": "second",
+		"second":     "latest-second",
+	}
+
+	if !reflect.DeepEqual(renames, want) {
+		t.Errorf("Renames() = %v, want %v", renames, want)
+	}
+}
+
+func TestHistoryIncludesRenamedFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(map[string]string{"artifacts/the-second.md": linkOnlyArtifact})
+	repo.commit(map[string]string{"artifacts/second.md": linkOnlyArtifact}, "artifacts/the-second.md")
+
+	artifacts, err := History(context.Background(), repo.dir, artifactsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slugs := make([]string, 0, len(artifacts))
+
+	for _, artifact := range artifacts {
+		slugs = append(slugs, artifact.Slug)
+	}
+
+	sort.Strings(slugs)
+
+	if want := []string{"second", "the-second"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("History() slugs = %v, want %v", slugs, want)
+	}
+}
+
+func TestHeadSlugs(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(map[string]string{
+		"artifacts/first.md":      linkOnlyArtifact,
+		"artifacts/the-second.md": linkOnlyArtifact,
+		"artifacts/third.md":      linkOnlyArtifact,
+		"README.md":               "Not an artifact",
+	})
+	repo.commit(map[string]string{"artifacts/second.md": linkOnlyArtifact}, "artifacts/the-second.md", "artifacts/third.md")
+
+	slugs, err := HeadSlugs(repo.dir, artifactsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct{}{"first": {}, "second": {}}
+
+	if !reflect.DeepEqual(slugs, want) {
+		t.Errorf("HeadSlugs() = %v, want %v", slugs, want)
+	}
+}
+
+func TestCurrentSlug(t *testing.T) {
+	renames := map[string]string{
+		"the-second": "second",
+		"second":     "latest-second",
+		"first":      "the-first",
+		"the-first":  "first",
+		"deleted":    "also-deleted",
+	}
+
+	current := map[string]struct{}{"latest-second": {}, "first": {}, "third": {}}
+
+	tests := []struct {
+		slug      string
+		want      string
+		isCurrent bool
+	}{
+		{slug: "third", want: "third", isCurrent: true},
+		{slug: "the-second", want: "latest-second", isCurrent: true},
+		{slug: "second", want: "latest-second", isCurrent: true},
+		// The artifact was renamed back to its original slug.
+		{slug: "the-first", want: "first", isCurrent: true},
+		{slug: "first", want: "first", isCurrent: true},
+		{slug: "deleted", want: "", isCurrent: false},
+		{slug: "never-renamed", want: "", isCurrent: false},
+	}
+
+	for _, test := range tests {
+		if got, isCurrent := CurrentSlug(test.slug, renames, current); got != test.want || isCurrent != test.isCurrent {
+			t.Errorf("CurrentSlug(%q) = %q, %t, want %q, %t", test.slug, got, isCurrent, test.want, test.isCurrent)
+		}
+	}
+}
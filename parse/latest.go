@@ -0,0 +1,102 @@
+package parse
+
+import (
+	"sort"
+)
+
+// isNewer returns whether `a` was committed after `b`. Artifacts from the
+// working tree have no commit and are newer than any commit.
+func isNewer(a, b Artifact) bool {
+	switch {
+	case a.Commit == nil:
+		return b.Commit != nil
+	case b.Commit == nil:
+		return false
+	default:
+		return a.Commit.Date.After(b.Commit.Date)
+	}
+}
+
+// NewestFirst returns a copy of `artifacts` sorted from most to least
+// recently committed. It doesn't modify `artifacts`.
+func NewestFirst(artifacts []Artifact) []Artifact {
+	sorted := make([]Artifact, len(artifacts))
+	copy(sorted, artifacts)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return isNewer(sorted[i], sorted[j])
+	})
+
+	return sorted
+}
+
+// Latest returns the most recent version of each artifact in `artifacts`,
+// from most to least recently committed.
+func Latest(artifacts []Artifact) []Artifact {
+	seen := make(map[string]struct{})
+	latest := make([]Artifact, 0)
+
+	for _, artifact := range NewestFirst(artifacts) {
+		if _, exists := seen[artifact.Slug]; exists {
+			continue
+		}
+
+		seen[artifact.Slug] = struct{}{}
+		latest = append(latest, artifact)
+	}
+
+	return latest
+}
+
+// ArtifactHistory is every version of an artifact.
+type ArtifactHistory struct {
+	// Latest is the most recent version of the artifact.
+	Latest Artifact `json:"latest"`
+
+	// FirstSeen is the commit which added the artifact.
+	FirstSeen *ArtifactCommit `json:"firstSeen"`
+
+	// LastModified is the commit which last changed the artifact.
+	LastModified *ArtifactCommit `json:"lastModified"`
+
+	// Revisions is every version of the artifact, from least to most recent.
+	Revisions []Artifact `json:"revisions"`
+}
+
+// GroupBySlug returns the history of each artifact in `artifacts`, keyed by
+// its slug.
+func GroupBySlug(artifacts []Artifact) map[string]ArtifactHistory {
+	groups := make(map[string]ArtifactHistory)
+	sorted := NewestFirst(artifacts)
+
+	// Go from least to most recent, so that each version replaces the last.
+	for i := len(sorted) - 1; i >= 0; i-- {
+		artifact := sorted[i]
+
+		group, exists := groups[artifact.Slug]
+		if !exists {
+			group.FirstSeen = artifact.Commit
+		}
+
+		group.Latest = artifact
+		group.LastModified = artifact.Commit
+		group.Revisions = append(group.Revisions, artifact)
+		groups[artifact.Slug] = group
+	}
+
+	return groups
+}
+
+// WithSlugs returns the versions in `artifacts` of the artifacts whose slug is
+// in `slugs`.
+func WithSlugs(artifacts []Artifact, slugs map[string]struct{}) []Artifact {
+	matches := make([]Artifact, 0, len(artifacts))
+
+	for _, artifact := range artifacts {
+		if _, exists := slugs[artifact.Slug]; exists {
+			matches = append(matches, artifact)
+		}
+	}
+
+	return matches
+}
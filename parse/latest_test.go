@@ -0,0 +1,83 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// version returns a version of the artifact `slug` committed on day `day` of
+// January 2022, or from the working tree if `day` is 0.
+func version(slug string, day int) Artifact {
+	artifact := Artifact{Path: "artifacts/" + slug + ArtifactFileExtension, Slug: slug}
+
+	if day != 0 {
+		artifact.Commit = &ArtifactCommit{
+			Rev:  slug + "-" + strconv.Itoa(day),
+			Date: time.Date(2022, time.January, day, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	return artifact
+}
+
+func TestLatest(t *testing.T) {
+	tests := []struct {
+		name      string
+		artifacts []Artifact
+		want      []Artifact
+	}{
+		{
+			name:      "empty",
+			artifacts: nil,
+			want:      []Artifact{},
+		},
+		{
+			name:      "most recent version",
+			artifacts: []Artifact{version("a", 1), version("b", 2), version("a", 3), version("b", 1)},
+			want:      []Artifact{version("a", 3), version("b", 2)},
+		},
+		{
+			name:      "in any order",
+			artifacts: []Artifact{version("a", 3), version("a", 1), version("a", 2)},
+			want:      []Artifact{version("a", 3)},
+		},
+		{
+			name:      "working tree is newest",
+			artifacts: []Artifact{version("a", 2), version("a", 0), version("a", 5)},
+			want:      []Artifact{version("a", 0)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Latest(test.artifacts); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Latest() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGroupBySlug(t *testing.T) {
+	artifacts := []Artifact{version("a", 2), version("b", 4), version("a", 3), version("a", 1)}
+
+	want := map[string]ArtifactHistory{
+		"a": {
+			Latest:       version("a", 3),
+			FirstSeen:    version("a", 1).Commit,
+			LastModified: version("a", 3).Commit,
+			Revisions:    []Artifact{version("a", 1), version("a", 2), version("a", 3)},
+		},
+		"b": {
+			Latest:       version("b", 4),
+			FirstSeen:    version("b", 4).Commit,
+			LastModified: version("b", 4).Commit,
+			Revisions:    []Artifact{version("b", 4)},
+		},
+	}
+
+	if got := GroupBySlug(artifacts); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupBySlug() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// fieldSegmentRegex matches each segment of an `EntryField` path, like
+// `files`, `[0]`, and `cid` in `files[0].cid`.
+var fieldSegmentRegex = regexp.MustCompile(`\[(\d+)\]|([^.\[\]]+)`)
+
+// findFieldLine returns the line in the front matter parsed into `root` where
+// `field` is defined, relative to the start of the front matter. If only part
+// of the path exists, it returns the line of the innermost part that does. It
+// returns 0 if none of it exists.
+func findFieldLine(root *yaml.Node, field EntryField) int {
+	if root == nil || len(root.Content) == 0 {
+		return 0
+	}
+
+	node := root.Content[0]
+	line := 0
+
+	for _, segment := range fieldSegmentRegex.FindAllStringSubmatch(string(field), -1) {
+		switch indexStr, key := segment[1], segment[2]; {
+		case key != "" && node.Kind == yaml.MappingNode:
+			found := false
+
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					node = node.Content[i+1]
+					found = true
+
+					break
+				}
+			}
+
+			if !found {
+				return line
+			}
+		case indexStr != "" && node.Kind == yaml.SequenceNode:
+			index, err := strconv.Atoi(indexStr)
+			if err != nil || index >= len(node.Content) {
+				return line
+			}
+
+			node = node.Content[index]
+			line = node.Line
+		default:
+			return line
+		}
+	}
+
+	return line
+}
+
+// locateReasons returns a copy of `err` where each reason has the line in the
+// artifact file where its field is defined. `startLine` is the line in the
+// artifact file where `frontMatter` starts.
+func locateReasons(err InvalidArtifactError, frontMatter string, startLine int) InvalidArtifactError {
+	var root yaml.Node
+
+	if yaml.Unmarshal([]byte(frontMatter), &root) != nil {
+		return err
+	}
+
+	reasons := make([]InvalidArtifactReason, len(err.Reasons))
+
+	for i, reason := range err.Reasons {
+		reasons[i] = reason
+
+		if line := findFieldLine(&root, reason.Field); line > 0 {
+			reasons[i].Line = startLine + line - 1
+		}
+	}
+
+	return InvalidArtifactError{
+		FilePath: err.FilePath,
+		Reasons:  reasons,
+	}
+}
@@ -2,6 +2,8 @@ package parse
 
 import (
 	"time"
+
+	"github.com/acearchive/artifact-action/markdown"
 )
 
 const CurrentArtifactVersion = 3
@@ -11,12 +13,37 @@ const ArtifactFileExtension = ".md"
 type GenericEntry map[string]interface{}
 
 type Artifact struct {
-	Path   string          `json:"path"`
-	Slug   string          `json:"slug"`
+	Path string `json:"path"`
+	Slug string `json:"slug"`
+
+	// TitlePlain, TitleHTML, and SortKey are derived from the title, which
+	// may contain inline Markdown, so that every consumer renders and sorts
+	// titles the same way.
+	TitlePlain string `json:"titlePlain"`
+	TitleHTML  string `json:"titleHTML"`
+	SortKey    string `json:"sortKey"`
+
 	Commit *ArtifactCommit `json:"commit"`
 	Entry  GenericEntry    `json:"entry"`
 }
 
+// newArtifact returns an artifact with the fields derived from its title
+// filled in. It works with every schema version, since they all have a
+// title.
+func newArtifact(path, slug string, commit *ArtifactCommit, entry GenericEntry) Artifact {
+	title := entry.String(FieldTitle)
+
+	return Artifact{
+		Path:       path,
+		Slug:       slug,
+		TitlePlain: markdown.Plain(title),
+		TitleHTML:  markdown.HTML(title),
+		SortKey:    markdown.SortKey(title),
+		Commit:     commit,
+		Entry:      entry,
+	}
+}
+
 type ArtifactCommit struct {
 	Rev  string    `json:"rev"`
 	Date time.Time `json:"date"`
@@ -16,7 +16,10 @@ import (
 
 const frontMatterDelimiter = "---"
 
-var ErrNoFrontMatter = errors.New("this file has no front matter")
+var (
+	ErrNoFrontMatter           = errors.New("this file has no front matter")
+	ErrUnterminatedFrontMatter = errors.New("this file's front matter is never closed")
+)
 
 type ArtifactParseError struct {
 	Path   string
@@ -39,9 +42,9 @@ func isWhitespace(line string) bool {
 	return strings.TrimSpace(line) == ""
 }
 
-func extractFrontMatter(file io.ReadCloser) (string, error) {
-	var err error
-
+// extractFrontMatter returns the front matter of an artifact file along with
+// the line number of its first line in the file.
+func extractFrontMatter(file io.ReadCloser) (_ string, _ int, err error) {
 	defer func() {
 		closeErr := file.Close()
 		if closeErr != nil && err == nil {
@@ -50,15 +53,21 @@ func extractFrontMatter(file io.ReadCloser) (string, error) {
 	}()
 
 	scanner := bufio.NewScanner(file)
+	lineNumber := 0
 
 	// Find the start of the front matter block.
 findStart:
 	for {
-		scanner.Scan()
-		if scanner.Err() != nil {
-			return "", err
+		if !scanner.Scan() {
+			if scanner.Err() != nil {
+				return "", 0, scanner.Err()
+			}
+
+			return "", 0, ErrNoFrontMatter
 		}
 
+		lineNumber++
+
 		currentLine := scanner.Text()
 
 		switch {
@@ -67,17 +76,21 @@ findStart:
 		case isFrontMatterDelimiter(currentLine):
 			break findStart
 		default:
-			return "", ErrNoFrontMatter
+			return "", 0, ErrNoFrontMatter
 		}
 	}
 
+	startLine := lineNumber + 1
+
 	var frontMatter strings.Builder
 
 	for {
-		scanner.Scan()
+		if !scanner.Scan() {
+			if scanner.Err() != nil {
+				return "", 0, scanner.Err()
+			}
 
-		if scanner.Err() != nil {
-			return "", err
+			return "", 0, ErrUnterminatedFrontMatter
 		}
 
 		currentLine := scanner.Text()
@@ -89,7 +102,7 @@ findStart:
 		frontMatter.WriteString(currentLine + "\n")
 	}
 
-	return frontMatter.String(), nil
+	return frontMatter.String(), startLine, nil
 }
 
 func parseArtifactEntry(frontMatter string) (ArtifactEntry, error) {
@@ -150,6 +163,23 @@ func (e GenericEntry) ToTyped(value interface{}) {
 	}
 }
 
+// Typed returns the entry as an `ArtifactEntry`. Unlike `ToTyped`, it returns
+// an error instead of exiting if the entry doesn't fit the current schema.
+func (e GenericEntry) Typed() (ArtifactEntry, error) {
+	var entry ArtifactEntry
+
+	rawJSON, err := json.Marshal(e)
+	if err != nil {
+		return ArtifactEntry{}, err
+	}
+
+	if err := json.Unmarshal(rawJSON, &entry); err != nil {
+		return ArtifactEntry{}, err
+	}
+
+	return entry, nil
+}
+
 func (a Artifact) Version() int {
 	entry := struct {
 		Version int `json:"version"`
@@ -1,35 +1,59 @@
 package parse
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/acearchive/artifact-action/logger"
 )
 
-func logArtifactErrors(artifactErrors []error) {
-	logger.LogError(ErrInvalidArtifactFiles)
-	logger.LogErrorGroup("Artifact file errors:", artifactErrors)
-	logger.Exit()
+// InvalidArtifactFilesError is returned by `Tree` when one or more artifact
+// files are invalid. It wraps `ErrInvalidArtifactFiles`.
+type InvalidArtifactFilesError struct {
+	// Errors contains an `ArtifactParseError` or `InvalidArtifactError` for
+	// each invalid artifact file.
+	Errors []error
 }
 
-func Tree(workspacePath, artifactsPath string) ([]Artifact, error) {
+func (e InvalidArtifactFilesError) Error() string {
+	return ErrInvalidArtifactFiles.Error()
+}
+
+func (e InvalidArtifactFilesError) Unwrap() error {
+	return ErrInvalidArtifactFiles
+}
+
+// WalkTree parses and validates the artifact files in the working tree,
+// calling `fn` with each artifact as soon as it's parsed. If any artifact
+// files are invalid, the reasons are logged and an
+// `InvalidArtifactFilesError` is returned once every artifact file has been
+// parsed. Artifact files which fail validation are still passed to `fn`, but
+// artifact files which can't be parsed at all are not.
+func WalkTree(ctx context.Context, workspacePath, artifactsPath string, fn func(Artifact) error) error {
+	startTime := time.Now()
+
 	artifactFilePaths, err := findArtifactFiles(workspacePath, artifactsPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	logger.Printf("Found %d artifact files in the tree\n", len(artifactFilePaths))
+	logger.LogInfo(fmt.Sprintf("Found %d artifact files in the tree", len(artifactFilePaths)), logger.Phase("tree"))
 
 	var artifactErrors []error
 
-	artifacts := make([]Artifact, 0, len(artifactFilePaths))
-
 	for _, filePath := range artifactFilePaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		relativePath, err := filepath.Rel(workspacePath, filePath)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		registerErr := func(reason error) {
@@ -41,10 +65,10 @@ func Tree(workspacePath, artifactsPath string) ([]Artifact, error) {
 
 		artifactFile, err := os.Open(filePath)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		frontMatter, err := extractFrontMatter(artifactFile)
+		frontMatter, frontMatterLine, err := extractFrontMatter(artifactFile)
 		if err != nil {
 			registerErr(err)
 			continue
@@ -57,22 +81,50 @@ func Tree(workspacePath, artifactsPath string) ([]Artifact, error) {
 		}
 
 		if validateErr := ValidateEntry(entry, relativePath); validateErr != nil {
+			var invalidErr InvalidArtifactError
+
+			if errors.As(validateErr, &invalidErr) {
+				validateErr = locateReasons(invalidErr, frontMatter, frontMatterLine)
+			}
+
 			artifactErrors = append(artifactErrors, validateErr)
 		}
 
-		artifacts = append(artifacts, Artifact{
-			Path:   relativePath,
-			Slug:   strings.TrimSuffix(filepath.Base(relativePath), ArtifactFileExtension),
-			Commit: nil,
-			Entry:  entry.ToGeneric(),
-		})
+		slug := strings.TrimSuffix(filepath.Base(relativePath), ArtifactFileExtension)
+
+		logger.LogDebug(fmt.Sprintf("Parsed artifact file: %s", relativePath), logger.Slug(slug), logger.Phase("tree"))
+
+		if err := fn(newArtifact(relativePath, slug, nil, entry.ToGeneric())); err != nil {
+			return err
+		}
 	}
 
 	if len(artifactErrors) != 0 {
-		logArtifactErrors(artifactErrors)
-	} else {
-		logger.Println("All artifact files in the tree are valid")
+		logger.LogErrorGroup("Artifact file errors:", artifactErrors)
+		return InvalidArtifactFilesError{Errors: artifactErrors}
+	}
+
+	logger.LogInfo("All artifact files in the tree are valid", logger.Phase("tree"), logger.Duration(time.Since(startTime)))
+
+	return nil
+}
+
+// Tree parses and validates the artifact files in the working tree. If any
+// artifact files are invalid, the reasons are logged and the artifacts are
+// returned along with an `InvalidArtifactFilesError`.
+func Tree(ctx context.Context, workspacePath, artifactsPath string) ([]Artifact, error) {
+	var artifacts []Artifact
+
+	err := WalkTree(ctx, workspacePath, artifactsPath, func(artifact Artifact) error {
+		artifacts = append(artifacts, artifact)
+		return nil
+	})
+
+	var invalidErr InvalidArtifactFilesError
+
+	if err != nil && !errors.As(err, &invalidErr) {
+		return nil, err
 	}
 
-	return artifacts, nil
+	return artifacts, err
 }
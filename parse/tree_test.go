@@ -0,0 +1,78 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const treeArtifact = `---
+version: 3
+title: "An artifact"
+description: "An artifact in the working tree"
+links:
+  - name: "Website"
+    url: "https://example.com"
+people: []
+identities: []
+fromYear: 1990
+decades: [1990]
+aliases: []
+---
+`
+
+// newTestTree returns a working tree with an artifact file for each slug in
+// `slugs`.
+func newTestTree(t *testing.T, slugs ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "artifacts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, slug := range slugs {
+		path := filepath.Join(dir, "artifacts", slug+ArtifactFileExtension)
+
+		if err := os.WriteFile(path, []byte(treeArtifact), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestWalkTreeStopsWhenCanceled(t *testing.T) {
+	dir := newTestTree(t, "first", "second", "third")
+
+	artifacts, err := Tree(context.Background(), dir, "artifacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(artifacts) != 3 {
+		t.Fatalf("Tree() returned %d artifacts, want 3", len(artifacts))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var slugs []string
+
+	err = WalkTree(ctx, dir, "artifacts", func(artifact Artifact) error {
+		slugs = append(slugs, artifact.Slug)
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WalkTree() = %v, want %v", err, context.Canceled)
+	}
+
+	if len(slugs) != 1 {
+		t.Errorf("WalkTree() walked %v after being canceled", slugs)
+	}
+}
@@ -17,9 +17,18 @@ var ErrInvalidArtifactFiles = errors.New("one or more artifact files are invalid
 // the website.
 var fileNameRegex = regexp.MustCompile(`^[\w\d][\w\d-]*[\w\d](\.[\w\d]+)*$`)
 
+// RuleParse is the rule for artifact files which can't be parsed at all.
+const RuleParse = "parse"
+
 type InvalidArtifactReason struct {
+	// Rule identifies the validator which reported this reason.
+	Rule   string
 	Field  EntryField
 	Reason string
+
+	// Line is the line in the artifact file where the field is defined, or 0
+	// if it isn't known.
+	Line int
 }
 
 type InvalidArtifactError struct {
@@ -55,6 +64,14 @@ type ErrorCallback func(field EntryField, reason string)
 
 type FieldValidator func(entry ArtifactEntry, reportError ErrorCallback)
 
+// Validator is a `FieldValidator` along with a rule which identifies it in
+// validation reports.
+type Validator struct {
+	Rule        string
+	Description string
+	Validate    FieldValidator
+}
+
 func validateIsNotEmpty(field EntryField, value string, reportError ErrorCallback) {
 	if value == "" {
 		reportError(field, "can not be empty")
@@ -229,28 +246,38 @@ func validateLinks(entry ArtifactEntry, reportError ErrorCallback) {
 	}
 }
 
-var allValidators = []FieldValidator{
-	validateVersion,
-	validateTitle,
-	validateDescription,
-	validateFromYear,
-	validateToYear,
-	validateDecades,
-	validateAliases,
-	validatePeople,
-	validateIdentities,
-	validateFiles,
-	validateLinks,
+var allValidators = []Validator{
+	{Rule: "version", Description: "The schema version must be the current version.", Validate: validateVersion},
+	{Rule: "title", Description: "The title can not be empty.", Validate: validateTitle},
+	{Rule: "description", Description: "The description can not be empty.", Validate: validateDescription},
+	{Rule: "from-year", Description: "The starting year must be set.", Validate: validateFromYear},
+	{Rule: "to-year", Description: "The ending year can not come before the starting year.", Validate: validateToYear},
+	{Rule: "decades", Description: "The decades must cover the years of the artifact in order.", Validate: validateDecades},
+	{Rule: "aliases", Description: "The aliases must be unique and can not contain slashes.", Validate: validateAliases},
+	{Rule: "people", Description: "The people can not contain duplicates.", Validate: validatePeople},
+	{Rule: "identities", Description: "The identities can not contain duplicates.", Validate: validateIdentities},
+	{Rule: "files", Description: "Each file must have a name, a valid CID, and a legal file name.", Validate: validateFiles},
+	{Rule: "links", Description: "Each link must have a name and an HTTPS URL.", Validate: validateLinks},
+}
+
+// Validators returns every validator that artifact files are checked against,
+// in the order they're run.
+func Validators() []Validator {
+	return append([]Validator(nil), allValidators...)
 }
 
 func ValidateEntry(entry ArtifactEntry, filePath string) error {
 	var reasons []InvalidArtifactReason
 
 	for _, validator := range allValidators {
-		validator(entry, func(field EntryField, reason string) {
+		rule := validator.Rule
+
+		validator.Validate(entry, func(field EntryField, reason string) {
 			reasons = append(reasons, InvalidArtifactReason{
+				Rule:   rule,
 				Field:  field,
 				Reason: reason,
+				Line:   0,
 			})
 		})
 	}
@@ -2,6 +2,8 @@ package pin
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/acearchive/artifact-action/cfg"
 	"github.com/acearchive/artifact-action/logger"
@@ -21,11 +23,32 @@ import (
 // services.
 const rootMetaKey = "lgbt.acearchive.artifact-action.root"
 
+// Stats describes how far pinning got. It's returned even when pinning fails
+// part way through so that we can report what was completed.
+type Stats struct {
+	// AlreadyPinned is the number of files which were skipped because they
+	// were already pinned.
+	AlreadyPinned int
+
+	// ToPin is the number of files which needed to be pinned.
+	ToPin int
+
+	// Pinned is the files which were pinned, in the order they were pinned.
+	// Nothing is pinned in a dry run.
+	Pinned []cid.Cid
+
+	// RootPinned is whether the root directory was pinned, which it never is
+	// in a dry run.
+	RootPinned bool
+}
+
 func rootMeta() map[string]string {
 	return map[string]string{rootMetaKey: "true"}
 }
 
 func listPins(ctx context.Context, client *pinning.Client) (parse.ContentSet, error) {
+	startTime := time.Now()
+
 	existingPins, errChan := client.Ls(ctx, pinning.PinOpts.FilterStatus(pinning.StatusPinned))
 
 	existingContent := make(parse.ContentSet)
@@ -38,13 +61,12 @@ func listPins(ctx context.Context, client *pinning.Client) (parse.ContentSet, er
 		return nil, err
 	}
 
-	logger.Printf("Found %d pins\n", len(existingContent))
+	logger.LogInfo(fmt.Sprintf("Found %d pins", len(existingContent)), logger.Phase("pin"), logger.Duration(time.Since(startTime)))
 
 	return existingContent, nil
 }
 
-func pinDeduplicated(ctx context.Context, client *pinning.Client, fileCids []cid.Cid, rootCid cid.Cid, alreadyPinned parse.ContentSet) error {
-
+func pinDeduplicated(ctx context.Context, client *pinning.Client, fileCids []cid.Cid, rootCid cid.Cid, alreadyPinned parse.ContentSet) (Stats, error) {
 	filesToUpload := make([]cid.Cid, 0, len(fileCids))
 
 	for _, id := range fileCids {
@@ -53,36 +75,77 @@ func pinDeduplicated(ctx context.Context, client *pinning.Client, fileCids []cid
 		}
 	}
 
-	logger.Printf("Skipping %d files that are already pinned\n", len(fileCids)-len(filesToUpload))
-	logger.Printf("Pinning %d files\n", len(filesToUpload))
+	stats := Stats{
+		AlreadyPinned: len(fileCids) - len(filesToUpload),
+		ToPin:         len(filesToUpload),
+		Pinned:        make([]cid.Cid, 0, len(filesToUpload)),
+		RootPinned:    false,
+	}
+
+	logger.LogInfo(fmt.Sprintf("Skipping %d files that are already pinned", stats.AlreadyPinned), logger.Phase("pin"))
+	logger.LogInfo(fmt.Sprintf("Pinning %d files", stats.ToPin), logger.Phase("pin"))
 
 	for currentIndex, currentCid := range filesToUpload {
-		logger.Printf("Pinning (%d/%d): %s\n", currentIndex+1, len(filesToUpload), currentCid.String())
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		if cfg.DryRun() {
+			logger.LogInfo(
+				fmt.Sprintf("Would pin (%d/%d): %s", currentIndex+1, len(filesToUpload), currentCid.String()),
+				logger.Cid(currentCid),
+				logger.Phase("pin"),
+			)
 
-		if !cfg.DryRun() {
-			if _, err := client.Add(ctx, currentCid); err != nil {
-				return err
-			}
+			continue
 		}
-	}
 
-	logger.Printf("\nPinning the root directory: /ipfs/%s\n", rootCid.String())
+		startTime := time.Now()
 
-	if !cfg.DryRun() {
-		if _, err := client.Add(ctx, rootCid, pinning.PinOpts.AddMeta(rootMeta())); err != nil {
-			return err
+		if _, err := client.Add(ctx, currentCid); err != nil {
+			return stats, err
 		}
+
+		logger.LogInfo(
+			fmt.Sprintf("Pinned (%d/%d): %s", currentIndex+1, len(filesToUpload), currentCid.String()),
+			logger.Cid(currentCid),
+			logger.Phase("pin"),
+			logger.Duration(time.Since(startTime)),
+		)
+
+		stats.Pinned = append(stats.Pinned, currentCid)
+	}
+
+	if cfg.DryRun() {
+		logger.LogInfo(fmt.Sprintf("Would pin the root directory: /ipfs/%s", rootCid.String()), logger.Cid(rootCid), logger.Phase("pin"))
+
+		return stats, nil
 	}
 
-	return nil
+	startTime := time.Now()
+
+	if _, err := client.Add(ctx, rootCid, pinning.PinOpts.AddMeta(rootMeta())); err != nil {
+		return stats, err
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("Pinned the root directory: /ipfs/%s", rootCid.String()),
+		logger.Cid(rootCid),
+		logger.Phase("pin"),
+		logger.Duration(time.Since(startTime)),
+	)
+
+	stats.RootPinned = true
+
+	return stats, nil
 }
 
-func Pin(ctx context.Context, endpoint, token string, fileCids []cid.Cid, rootCid cid.Cid) error {
+func Pin(ctx context.Context, endpoint, token string, fileCids []cid.Cid, rootCid cid.Cid) (Stats, error) {
 	client := pinning.NewClient(endpoint, token)
 
 	existingContent, err := listPins(ctx, client)
 	if err != nil {
-		return err
+		return Stats{}, err
 	}
 
 	return pinDeduplicated(ctx, client, fileCids, rootCid, existingContent)
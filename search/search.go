@@ -0,0 +1,218 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acearchive/artifact-action/markdown"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// The index is serialized in the format of MiniSearch
+// (https://github.com/lucaong/minisearch), so the site can load it with
+// `MiniSearch.loadJSON` instead of indexing every artifact on each deploy. The
+// tokenizer and term processing match the MiniSearch defaults, so queries are
+// processed the same way as the documents.
+
+// serializationVersion is the version of the MiniSearch serialization format.
+const serializationVersion = 2
+
+// IDField is the field which identifies each document.
+const IDField = "slug"
+
+// Fields are the fields which are indexed, in order of their field ID.
+var Fields = []string{"title", "description", "people", "identities", "aliases"}
+
+// StoredFields are the fields which are stored in the index to display search
+// results.
+var StoredFields = []string{"title", "description"}
+
+// tokenPattern is the pattern MiniSearch splits text on by default.
+var tokenPattern = regexp.MustCompile(`[\n\r\p{Z}\p{P}]+`)
+
+// Index is a prebuilt MiniSearch index.
+type Index struct {
+	DocumentCount        int                          `json:"documentCount"`
+	NextID               int                          `json:"nextId"`
+	DocumentIDs          map[string]string            `json:"documentIds"`
+	FieldIDs             map[string]int               `json:"fieldIds"`
+	FieldLength          map[string][]int             `json:"fieldLength"`
+	AverageFieldLength   []float64                    `json:"averageFieldLength"`
+	StoredFields         map[string]map[string]string `json:"storedFields"`
+	DirtCount            int                          `json:"dirtCount"`
+	Index                []term                       `json:"index"`
+	SerializationVersion int                          `json:"serializationVersion"`
+}
+
+// term is a term in the inverted index, with the number of times it occurs
+// in each field of each document, by field ID and then document ID. It's
+// serialized as a `[term, frequencies]` pair.
+type term struct {
+	Term        string
+	Frequencies map[string]map[string]int
+}
+
+func (t term) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{t.Term, t.Frequencies})
+}
+
+// document is the text of each field of an artifact.
+type document map[string]string
+
+// newDocument returns the document for an artifact, with the Markdown in its
+// title and description converted to plain text. List fields are joined with
+// commas like in JavaScript.
+func newDocument(artifact parse.Artifact) document {
+	entry := artifact.Entry
+
+	return document{
+		"title":       artifact.TitlePlain,
+		"description": markdown.Plain(strings.TrimSpace(entry.String(parse.FieldDescription))),
+		"people":      strings.Join(entry.Strings(parse.FieldPeople), ","),
+		"identities":  strings.Join(entry.Strings(parse.FieldIdentities), ","),
+		"aliases":     strings.Join(entry.Strings(parse.FieldAliases), ","),
+	}
+}
+
+// tokenize splits text into tokens like the default MiniSearch tokenizer,
+// which may produce empty tokens at the start and end.
+func tokenize(text string) []string {
+	return tokenPattern.Split(text, -1)
+}
+
+// uniqueCount returns the number of distinct tokens in `tokens`, which is
+// what MiniSearch uses as the length of a field.
+func uniqueCount(tokens []string) int {
+	unique := make(map[string]struct{}, len(tokens))
+
+	for _, token := range tokens {
+		unique[token] = struct{}{}
+	}
+
+	return len(unique)
+}
+
+// builder builds an index one document at a time.
+type builder struct {
+	index Index
+
+	// terms is the frequencies of each term, by field ID and then document
+	// ID.
+	terms map[string]map[string]map[string]int
+
+	// totalFieldLength is the sum of the length of each field in every
+	// document, by field ID.
+	totalFieldLength []int
+}
+
+func (b *builder) addTerm(fieldID, documentID, text string) {
+	fields, exists := b.terms[text]
+	if !exists {
+		fields = make(map[string]map[string]int)
+		b.terms[text] = fields
+	}
+
+	if fields[fieldID] == nil {
+		fields[fieldID] = make(map[string]int)
+	}
+
+	fields[fieldID][documentID]++
+}
+
+func (b *builder) add(shortID int, artifact parse.Artifact) {
+	idx := &b.index
+	documentID := strconv.Itoa(shortID)
+	doc := newDocument(artifact)
+
+	idx.DocumentIDs[documentID] = artifact.Slug
+	idx.FieldLength[documentID] = make([]int, len(Fields))
+	idx.StoredFields[documentID] = make(map[string]string, len(StoredFields))
+
+	for _, field := range StoredFields {
+		idx.StoredFields[documentID][field] = doc[field]
+	}
+
+	for fieldID, field := range Fields {
+		tokens := tokenize(doc[field])
+		idx.FieldLength[documentID][fieldID] = uniqueCount(tokens)
+		b.totalFieldLength[fieldID] += idx.FieldLength[documentID][fieldID]
+
+		for _, token := range tokens {
+			if processed := strings.ToLower(token); processed != "" {
+				b.addTerm(strconv.Itoa(fieldID), documentID, processed)
+			}
+		}
+	}
+}
+
+// Build returns the search index of the most recent version of each artifact
+// in `artifacts`. Each document's internal ID is its position when sorted by
+// slug, so the index is the same each time it's built from the same
+// artifacts.
+func Build(artifacts []parse.Artifact) Index {
+	latest := parse.Latest(artifacts)
+
+	sort.Slice(latest, func(i, j int) bool {
+		return latest[i].Slug < latest[j].Slug
+	})
+
+	b := builder{
+		index: Index{
+			DocumentCount:        len(latest),
+			NextID:               len(latest),
+			DocumentIDs:          make(map[string]string, len(latest)),
+			FieldIDs:             make(map[string]int, len(Fields)),
+			FieldLength:          make(map[string][]int, len(latest)),
+			AverageFieldLength:   make([]float64, len(Fields)),
+			StoredFields:         make(map[string]map[string]string, len(latest)),
+			DirtCount:            0,
+			Index:                nil,
+			SerializationVersion: serializationVersion,
+		},
+		terms:            make(map[string]map[string]map[string]int),
+		totalFieldLength: make([]int, len(Fields)),
+	}
+
+	for fieldID, field := range Fields {
+		b.index.FieldIDs[field] = fieldID
+	}
+
+	for shortID, artifact := range latest {
+		b.add(shortID, artifact)
+	}
+
+	if len(latest) > 0 {
+		for fieldID, total := range b.totalFieldLength {
+			b.index.AverageFieldLength[fieldID] = float64(total) / float64(len(latest))
+		}
+	}
+
+	b.index.Index = make([]term, 0, len(b.terms))
+
+	for text, frequencies := range b.terms {
+		b.index.Index = append(b.index.Index, term{Term: text, Frequencies: frequencies})
+	}
+
+	sort.Slice(b.index.Index, func(i, j int) bool {
+		return b.index.Index[i].Term < b.index.Index[j].Term
+	})
+
+	return b.index
+}
+
+// WriteIndex writes the search index of the artifacts as JSON.
+func WriteIndex(w io.Writer, artifacts []parse.Artifact) error {
+	marshalledIndex, err := json.Marshal(Build(artifacts))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(marshalledIndex))
+
+	return err
+}
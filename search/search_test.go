@@ -0,0 +1,66 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestWriteIndex(t *testing.T) {
+	artifacts := []parse.Artifact{
+		testutil.Artifact("orlando-the-asexual-manifesto", testutil.Date(2), parse.GenericEntry{
+			string(parse.FieldTitle):       "*The Asexual Manifesto*",
+			string(parse.FieldDescription): "A paper by the _New York Radical Feminists_.",
+			string(parse.FieldPeople):      []interface{}{"Lisa Orlando", "New York Radical Feminists"},
+			string(parse.FieldIdentities):  []interface{}{"asexual", "aromantic"},
+			string(parse.FieldAliases):     []interface{}{"the-asexual-manifesto"},
+		}),
+		testutil.Artifact("ace-zine", testutil.Date(1), parse.GenericEntry{
+			string(parse.FieldTitle):       "Ace Zine",
+			string(parse.FieldDescription): "A zine by aces, for aces",
+			string(parse.FieldPeople):      []interface{}{},
+			string(parse.FieldIdentities):  []interface{}{"asexual"},
+		}),
+		// Only the most recent version of each artifact is indexed.
+		testutil.Artifact("orlando-the-asexual-manifesto", testutil.Date(1), parse.GenericEntry{
+			string(parse.FieldTitle): "An older title",
+		}),
+	}
+
+	var buf bytes.Buffer
+
+	if err := WriteIndex(&buf, artifacts); err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+
+	if err := json.Indent(&got, buf.Bytes(), "", "  "); err != nil {
+		t.Fatal(err)
+	}
+
+	goldenPath := filepath.Join("testdata", "two-documents.json")
+
+	if *update {
+		if err := os.WriteFile(goldenPath, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("WriteIndex() =\n%s\nwant\n%s", got.String(), want)
+	}
+}
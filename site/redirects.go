@@ -0,0 +1,280 @@
+package site
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+// maxCloudflareRedirects is the maximum number of static redirects Cloudflare
+// Pages supports in a `_redirects` file.
+const maxCloudflareRedirects = 2000
+
+// nginxVariable is the variable the nginx map sets to the redirect target.
+const nginxVariable = "$artifact_redirect"
+
+// Redirect is a redirect from a slug which is no longer the slug of an
+// artifact to the slug of the artifact it belongs to now.
+type Redirect struct {
+	From string
+
+	// To is the slug of the artifact to redirect to, or empty to redirect to
+	// the list of artifacts if it has no successor.
+	To string
+}
+
+// fileCids returns the CIDs of the files in `entry`.
+func fileCids(entry parse.GenericEntry) []string {
+	files := entry.Objects(parse.FieldFiles)
+	cids := make([]string, 0, len(files))
+
+	for _, file := range files {
+		if fileCid := file.String(parse.FieldFileCid); fileCid != "" {
+			cids = append(cids, fileCid)
+		}
+	}
+
+	return cids
+}
+
+// renamedTo follows the chain of renames from `slug` to the slug of a current
+// artifact, if it leads to one.
+func renamedTo(slug string, renames map[string]string, targets map[string]string) (string, bool) {
+	seen := map[string]struct{}{slug: {}}
+
+	for {
+		next, isRenamed := renames[slug]
+		if !isRenamed {
+			return "", false
+		}
+
+		if targets[next] == next {
+			return next, true
+		}
+
+		if _, isCycle := seen[next]; isCycle {
+			return "", false
+		}
+
+		seen[next] = struct{}{}
+		slug = next
+	}
+}
+
+// successor returns the slug of the artifact in `current` which the artifact
+// with the history `past` most likely became, which is the only current
+// artifact with a file in common with it.
+func successor(past parse.ArtifactHistory, cidOwners map[string]map[string]struct{}) (string, bool) {
+	candidates := make(map[string]struct{})
+
+	for _, revision := range past.Revisions {
+		for _, fileCid := range fileCids(revision.Entry) {
+			for slug := range cidOwners[fileCid] {
+				candidates[slug] = struct{}{}
+			}
+		}
+	}
+
+	if len(candidates) != 1 {
+		return "", false
+	}
+
+	for slug := range candidates {
+		return slug, true
+	}
+
+	return "", false
+}
+
+func sortedSlugs(histories map[string]parse.ArtifactHistory) []string {
+	slugs := make([]string, 0, len(histories))
+
+	for slug := range histories {
+		slugs = append(slugs, slug)
+	}
+
+	sort.Strings(slugs)
+
+	return slugs
+}
+
+// Redirects returns a redirect for every alias and every historical slug of
+// the artifacts, sorted by the slug they redirect from. `current` is the
+// artifacts in the tree, `history` is every version of every artifact, and
+// `renames` is the renames of artifact files git detected, as returned by
+// `parse.Renames`.
+//
+// Aliases in any version of an artifact redirect to it. A slug which is no
+// longer in the tree redirects to the current artifact its file was renamed
+// to, or else to the only current artifact which shares a file with it, and
+// to the list of artifacts if there is neither, so that old URLs never 404.
+func Redirects(current, history []parse.Artifact, renames map[string]string) []Redirect {
+	targets := make(map[string]string)
+	cidOwners := make(map[string]map[string]struct{})
+
+	for _, artifact := range current {
+		targets[artifact.Slug] = artifact.Slug
+
+		for _, fileCid := range fileCids(artifact.Entry) {
+			if cidOwners[fileCid] == nil {
+				cidOwners[fileCid] = make(map[string]struct{})
+			}
+
+			cidOwners[fileCid][artifact.Slug] = struct{}{}
+		}
+	}
+
+	allVersions := make([]parse.Artifact, 0, len(current)+len(history))
+	allVersions = append(allVersions, current...)
+	allVersions = append(allVersions, history...)
+
+	histories := parse.GroupBySlug(allVersions)
+
+	var redirects []Redirect
+
+	addRedirect := func(from, to string) {
+		if existing, exists := targets[from]; exists {
+			if existing != to && existing != from {
+				logger.LogWarning(
+					fmt.Sprintf("%s already redirects to %s, so it can't redirect to %s", from, existing, to),
+					logger.Phase("redirects"),
+				)
+			}
+
+			return
+		}
+
+		targets[from] = to
+		redirects = append(redirects, Redirect{From: from, To: to})
+	}
+
+	// Aliases of current artifacts take precedence over guessing the
+	// successor of a historical slug.
+	for _, slug := range sortedSlugs(histories) {
+		if targets[slug] != slug {
+			continue
+		}
+
+		for _, revision := range histories[slug].Revisions {
+			for _, alias := range revision.Entry.Strings(parse.FieldAliases) {
+				addRedirect(alias, slug)
+			}
+		}
+	}
+
+	for _, slug := range sortedSlugs(histories) {
+		if targets[slug] == slug {
+			continue
+		}
+
+		to, isAlias := targets[slug]
+		if !isAlias {
+			var found bool
+
+			if to, found = renamedTo(slug, renames, targets); !found {
+				if to, found = successor(histories[slug], cidOwners); !found {
+					logger.LogWarning("The artifact has no successor, so it redirects to the list of artifacts", logger.Slug(slug), logger.Phase("redirects"))
+				}
+			}
+
+			addRedirect(slug, to)
+		}
+
+		for _, revision := range histories[slug].Revisions {
+			for _, alias := range revision.Entry.Strings(parse.FieldAliases) {
+				addRedirect(alias, to)
+			}
+		}
+	}
+
+	sort.Slice(redirects, func(i, j int) bool {
+		return redirects[i].From < redirects[j].From
+	})
+
+	return redirects
+}
+
+// redirectPaths returns the path of the page each redirect is from and to.
+func redirectPaths(redirect Redirect, base string) (string, string) {
+	return fmt.Sprintf("%s/%s", base, redirect.From), fmt.Sprintf("%s/%s", base, redirect.To)
+}
+
+// WriteNetlify writes the redirects as a Netlify `_redirects` file. Netlify
+// ignores trailing slashes when matching, so there's one rule per redirect.
+func WriteNetlify(w io.Writer, redirects []Redirect, siteURL string) error {
+	base, err := basePath(siteURL)
+	if err != nil {
+		return err
+	}
+
+	for _, redirect := range redirects {
+		from, to := redirectPaths(redirect, base)
+
+		if _, err := fmt.Fprintf(w, "%s %s 301\n", from, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteCloudflare writes the redirects as a Cloudflare Pages `_redirects`
+// file. Cloudflare matches paths exactly, so there's a rule for each redirect
+// with and without a trailing slash.
+func WriteCloudflare(w io.Writer, redirects []Redirect, siteURL string) error {
+	base, err := basePath(siteURL)
+	if err != nil {
+		return err
+	}
+
+	if rules := 2 * len(redirects); rules > maxCloudflareRedirects {
+		logger.LogWarning(
+			fmt.Sprintf("There are %d redirect rules, which is more than Cloudflare Pages supports (%d)", rules, maxCloudflareRedirects),
+			logger.Phase("redirects"),
+		)
+	}
+
+	for _, redirect := range redirects {
+		from, to := redirectPaths(redirect, base)
+
+		if _, err := fmt.Fprintf(w, "%s %s 301\n%s/ %s 301\n", from, to, from, to); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteNginx writes the redirects as an nginx map from the requested URI to
+// the URI to redirect to, to be included in the `http` block.
+func WriteNginx(w io.Writer, redirects []Redirect, siteURL string) error {
+	base, err := basePath(siteURL)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(
+		w,
+		"# Redirect in the server block with:\n#   if (%s) { return 301 %s; }\nmap $uri %s {\n",
+		nginxVariable,
+		nginxVariable,
+		nginxVariable,
+	); err != nil {
+		return err
+	}
+
+	for _, redirect := range redirects {
+		from, to := redirectPaths(redirect, base)
+
+		if _, err := fmt.Fprintf(w, "%s\"%s\" \"%s\";\n%s\"%s/\" \"%s\";\n", indent, from, to, indent, from, to); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w, "}")
+
+	return err
+}
@@ -0,0 +1,150 @@
+package site
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func testArtifact(slug string, day int, cids []string, aliases []string) parse.Artifact {
+	files := make([]interface{}, len(cids))
+
+	for i, fileCid := range cids {
+		files[i] = testutil.File("", fileCid, "")
+	}
+
+	aliasValues := make([]interface{}, len(aliases))
+
+	for i, alias := range aliases {
+		aliasValues[i] = alias
+	}
+
+	return testutil.Artifact(slug, testutil.Date(day), parse.GenericEntry{
+		string(parse.FieldFiles):   files,
+		string(parse.FieldAliases): aliasValues,
+	})
+}
+
+func TestRedirects(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []parse.Artifact
+		history []parse.Artifact
+		renames map[string]string
+		want    []Redirect
+	}{
+		{
+			name:    "alias of a current artifact",
+			current: []parse.Artifact{testArtifact("first", 2, nil, []string{"the-first"})},
+			history: []parse.Artifact{testArtifact("first", 1, nil, []string{"old-alias"})},
+			renames: nil,
+			want: []Redirect{
+				{From: "old-alias", To: "first"},
+				{From: "the-first", To: "first"},
+			},
+		},
+		{
+			name:    "renamed link-only artifact",
+			current: []parse.Artifact{testArtifact("second", 2, nil, nil)},
+			history: []parse.Artifact{testArtifact("the-second", 1, nil, nil)},
+			renames: map[string]string{"the-second": "second"},
+			want:    []Redirect{{From: "the-second", To: "second"}},
+		},
+		{
+			name:    "chain of renames",
+			current: []parse.Artifact{testArtifact("third", 3, nil, nil)},
+			history: []parse.Artifact{testArtifact("first", 1, nil, nil), testArtifact("second", 2, nil, nil)},
+			renames: map[string]string{"first": "second", "second": "third"},
+			want: []Redirect{
+				{From: "first", To: "third"},
+				{From: "second", To: "third"},
+			},
+		},
+		{
+			name:    "rename takes precedence over a shared file",
+			current: []parse.Artifact{testArtifact("renamed", 2, nil, nil), testArtifact("other", 2, []string{"cid"}, nil)},
+			history: []parse.Artifact{testArtifact("old", 1, []string{"cid"}, nil)},
+			renames: map[string]string{"old": "renamed"},
+			want:    []Redirect{{From: "old", To: "renamed"}},
+		},
+		{
+			name:    "successor by shared file",
+			current: []parse.Artifact{testArtifact("merged", 2, []string{"cid"}, nil)},
+			history: []parse.Artifact{testArtifact("old", 1, []string{"cid"}, []string{"older"})},
+			renames: nil,
+			want: []Redirect{
+				{From: "old", To: "merged"},
+				{From: "older", To: "merged"},
+			},
+		},
+		{
+			name:    "ambiguous successor redirects to the index",
+			current: []parse.Artifact{testArtifact("a", 2, []string{"cid"}, nil), testArtifact("b", 2, []string{"cid"}, nil)},
+			history: []parse.Artifact{testArtifact("old", 1, []string{"cid"}, nil)},
+			renames: nil,
+			want:    []Redirect{{From: "old", To: ""}},
+		},
+		{
+			name:    "renamed to an artifact which was since deleted",
+			current: []parse.Artifact{testArtifact("unrelated", 3, nil, nil)},
+			history: []parse.Artifact{testArtifact("old", 1, nil, nil), testArtifact("new", 2, nil, nil)},
+			renames: map[string]string{"old": "new"},
+			want: []Redirect{
+				{From: "new", To: ""},
+				{From: "old", To: ""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Redirects(test.current, test.history, test.renames)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Redirects() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteRedirects(t *testing.T) {
+	redirects := []Redirect{
+		{From: "old", To: "new"},
+		{From: "gone", To: ""},
+	}
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer) error
+		want  string
+	}{
+		{
+			name:  "netlify",
+			write: func(w *bytes.Buffer) error { return WriteNetlify(w, redirects, "https://example.com/artifacts/") },
+			want:  "/artifacts/old /artifacts/new 301\n/artifacts/gone /artifacts/ 301\n",
+		},
+		{
+			name:  "cloudflare",
+			write: func(w *bytes.Buffer) error { return WriteCloudflare(w, redirects, "https://example.com/artifacts") },
+			want: "/artifacts/old /artifacts/new 301\n/artifacts/old/ /artifacts/new 301\n" +
+				"/artifacts/gone /artifacts/ 301\n/artifacts/gone/ /artifacts/ 301\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := test.write(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
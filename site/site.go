@@ -0,0 +1,22 @@
+package site
+
+import (
+	"net/url"
+	"strings"
+)
+
+// basePath returns the path of `siteURL`, without a trailing slash, which the
+// slug of each artifact is appended to.
+func basePath(siteURL string) (string, error) {
+	parsedURL, err := url.Parse(siteURL)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(parsedURL.Path, "/"), nil
+}
+
+// link returns the URL of the page for the artifact with the given slug.
+func link(siteURL, slug string) string {
+	return strings.TrimSuffix(siteURL, "/") + "/" + slug
+}
@@ -0,0 +1,74 @@
+package site
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+)
+
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+	// maxSitemapURLs is the maximum number of URLs in a single sitemap.
+	maxSitemapURLs = 50000
+
+	indent = "  "
+)
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// WriteSitemap writes a sitemap of the page for each artifact in `current`,
+// which is the artifacts in the tree. The last modification date of each page
+// is the date of the commit which last modified the artifact in `history`.
+func WriteSitemap(w io.Writer, current, history []parse.Artifact, siteURL string) error {
+	histories := parse.GroupBySlug(history)
+
+	slugs := make([]string, len(current))
+
+	for i, artifact := range current {
+		slugs[i] = artifact.Slug
+	}
+
+	sort.Strings(slugs)
+
+	if len(slugs) > maxSitemapURLs {
+		logger.LogWarning(
+			fmt.Sprintf("The sitemap has %d URLs, which is more than the limit of %d", len(slugs), maxSitemapURLs),
+			logger.Phase("sitemap"),
+		)
+	}
+
+	sitemap := urlSet{Xmlns: sitemapNamespace, URLs: make([]sitemapURL, len(slugs))}
+
+	for i, slug := range slugs {
+		sitemap.URLs[i] = sitemapURL{Loc: link(siteURL, slug), LastMod: ""}
+
+		// Artifacts which haven't been committed yet have no history.
+		if artifactHistory, exists := histories[slug]; exists && artifactHistory.LastModified != nil {
+			sitemap.URLs[i].LastMod = artifactHistory.LastModified.Date.UTC().Format(time.RFC3339)
+		}
+	}
+
+	marshalledSitemap, err := xml.MarshalIndent(sitemap, "", indent)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, marshalledSitemap)
+
+	return err
+}
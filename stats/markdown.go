@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeTable writes a Markdown table of `counts` to `builder`.
+func writeTable(builder *strings.Builder, heading, keyHeader, countHeader string, counts []Count) {
+	fmt.Fprintf(builder, "\n## %s\n\n", heading)
+
+	if len(counts) == 0 {
+		builder.WriteString("None.\n")
+		return
+	}
+
+	fmt.Fprintf(builder, "| %s | %s |\n", keyHeader, countHeader)
+	builder.WriteString("| --- | ---: |\n")
+
+	for _, count := range counts {
+		fmt.Fprintf(builder, "| %s | %d |\n", escapeCell(count.Key), count.Count)
+	}
+}
+
+func escapeCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+// Markdown returns the stats as a Markdown document.
+func (s Stats) Markdown() string {
+	var builder strings.Builder
+
+	builder.WriteString("# Archive statistics\n\n")
+	builder.WriteString("| Statistic | Value |\n")
+	builder.WriteString("| --- | ---: |\n")
+	fmt.Fprintf(&builder, "| Artifacts | %d |\n", s.Artifacts)
+	fmt.Fprintf(&builder, "| Versions | %d |\n", s.Versions)
+	fmt.Fprintf(&builder, "| Files | %d |\n", s.Files)
+	fmt.Fprintf(&builder, "| Unique CIDs | %d |\n", s.UniqueCids)
+	fmt.Fprintf(&builder, "| Artifacts without files | %d |\n", len(s.LinkOnly))
+
+	if s.TotalSize != nil {
+		fmt.Fprintf(&builder, "| Total size | %d bytes |\n", *s.TotalSize)
+	}
+
+	writeTable(&builder, "Artifacts over time", "Month", "Artifacts", s.OverTime)
+	writeTable(&builder, "Files by media type", "Media type", "Files", s.MediaTypes)
+	writeTable(&builder, "Artifacts by identity", "Identity", "Artifacts", s.Identities)
+	writeTable(&builder, "Artifacts by decade", "Decade", "Artifacts", s.Decades)
+
+	builder.WriteString("\n## Artifacts without files\n\n")
+
+	if len(s.LinkOnly) == 0 {
+		builder.WriteString("None.\n")
+	}
+
+	for _, slug := range s.LinkOnly {
+		fmt.Fprintf(&builder, "- `%s`\n", slug)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,238 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/acearchive/artifact-action/client"
+	"github.com/acearchive/artifact-action/logger"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+)
+
+// unknownMediaType is the media type of files which don't have one.
+const unknownMediaType = "unknown"
+
+// monthLayout is the layout of the months in `Stats.OverTime`.
+const monthLayout = "2006-01"
+
+// Count is the number of artifacts or files with a given key.
+type Count struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Stats summarizes the archive. Except where noted, it describes the most
+// recent version of each artifact which still exists, leaving out artifacts
+// which were renamed or deleted.
+type Stats struct {
+	// Artifacts is the number of artifacts.
+	Artifacts int `json:"artifacts"`
+
+	// Versions is the number of versions of every artifact in the history.
+	Versions int `json:"versions"`
+
+	// Files is the number of files.
+	Files int `json:"files"`
+
+	// UniqueCids is the number of unique CIDs in every version of every
+	// artifact in the history.
+	UniqueCids int `json:"uniqueCids"`
+
+	// TotalSize is the cumulative size in bytes of the files with those
+	// CIDs, or nil if there's no IPFS node to ask.
+	TotalSize *uint64 `json:"totalSize"`
+
+	// OverTime is the number of artifacts at the end of each month in which
+	// one was added, by the commit which added it. Only artifacts which still
+	// exist are counted.
+	OverTime []Count `json:"overTime"`
+
+	// MediaTypes is the number of files of each media type.
+	MediaTypes []Count `json:"mediaTypes"`
+
+	// Identities is the number of artifacts with each identity.
+	Identities []Count `json:"identities"`
+
+	// Decades is the number of artifacts in each decade.
+	Decades []Count `json:"decades"`
+
+	// LinkOnly is the slug of each artifact which has no files.
+	LinkOnly []string `json:"linkOnly"`
+}
+
+// sortedCounts returns the counts in `counts` from most to least common,
+// then by key.
+func sortedCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+
+	for key, count := range counts {
+		sorted = append(sorted, Count{Key: key, Count: count})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	return sorted
+}
+
+// overTime returns the cumulative number of artifacts at the end of each
+// month in which one was first seen.
+func overTime(histories map[string]parse.ArtifactHistory) []Count {
+	added := make(map[string]int)
+
+	for _, history := range histories {
+		if history.FirstSeen == nil {
+			continue
+		}
+
+		added[history.FirstSeen.Date.UTC().Format(monthLayout)]++
+	}
+
+	months := make([]string, 0, len(added))
+
+	for month := range added {
+		months = append(months, month)
+	}
+
+	sort.Strings(months)
+
+	counts := make([]Count, len(months))
+	total := 0
+
+	for i, month := range months {
+		total += added[month]
+		counts[i] = Count{Key: month, Count: total}
+	}
+
+	return counts
+}
+
+// Compute summarizes `artifacts`, which may include multiple versions of
+// each artifact, and `cidList`, which is the unique CIDs in them. `current` is
+// the slugs of the artifacts which still exist, as returned by
+// `parse.HeadSlugs`.
+func Compute(artifacts []parse.Artifact, current map[string]struct{}, cidList []cid.Cid) Stats {
+	histories := parse.GroupBySlug(parse.WithSlugs(artifacts, current))
+
+	mediaTypes := make(map[string]int)
+	identities := make(map[string]int)
+	decades := make(map[string]int)
+	linkOnly := make([]string, 0)
+	files := 0
+
+	for slug, history := range histories {
+		entry := history.Latest.Entry
+		entryFiles := entry.Objects(parse.FieldFiles)
+
+		if len(entryFiles) == 0 {
+			linkOnly = append(linkOnly, slug)
+		}
+
+		for _, file := range entryFiles {
+			mediaType := file.String(parse.FieldFileMediaType)
+			if mediaType == "" {
+				mediaType = unknownMediaType
+			}
+
+			mediaTypes[mediaType]++
+			files++
+		}
+
+		for _, identity := range entry.Strings(parse.FieldIdentities) {
+			identities[identity]++
+		}
+
+		for _, decade := range entry.Ints(parse.FieldDecades) {
+			decades[strconv.Itoa(decade)]++
+		}
+	}
+
+	sort.Strings(linkOnly)
+
+	decadeCounts := sortedCounts(decades)
+
+	// Decades read better in chronological order than by how common they
+	// are.
+	sort.Slice(decadeCounts, func(i, j int) bool {
+		return decadeCounts[i].Key < decadeCounts[j].Key
+	})
+
+	return Stats{
+		Artifacts:  len(histories),
+		Versions:   len(artifacts),
+		Files:      files,
+		UniqueCids: len(cidList),
+		TotalSize:  nil,
+		OverTime:   overTime(histories),
+		MediaTypes: sortedCounts(mediaTypes),
+		Identities: sortedCounts(identities),
+		Decades:    decadeCounts,
+		LinkOnly:   linkOnly,
+	}
+}
+
+// dagStat is the response of the `dag/stat` endpoint of the IPFS API. Older
+// versions of Kubo report the size of the DAG as `Size`, and newer versions
+// report it as `TotalSize`.
+type dagStat struct {
+	Size      uint64
+	TotalSize uint64
+}
+
+// TotalSize returns the sum of the size of the DAG of each CID in `cidList`,
+// according to the `dag stat` of the configured IPFS node.
+func TotalSize(ctx context.Context, cidList []cid.Cid) (uint64, error) {
+	startTime := time.Now()
+
+	ipfsClientGuard, err := client.New()
+	if err != nil {
+		return 0, err
+	}
+
+	ipfsClient := ipfsClientGuard.Lock()
+	defer ipfsClientGuard.Unlock()
+
+	var totalSize uint64
+
+	for _, fileCid := range cidList {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		var stat dagStat
+
+		if err := ipfsClient.Request("dag/stat", fileCid.String()).Option("progress", false).Exec(ctx, &stat); err != nil {
+			return 0, err
+		}
+
+		size := stat.Size
+		if stat.TotalSize != 0 {
+			size = stat.TotalSize
+		}
+
+		logger.LogDebug(
+			fmt.Sprintf("Got the size of %s: %d bytes", fileCid.String(), size),
+			logger.Cid(fileCid),
+			logger.Phase("stats"),
+		)
+
+		totalSize += size
+	}
+
+	logger.LogInfo(
+		fmt.Sprintf("Got the total size of %d files: %d bytes", len(cidList), totalSize),
+		logger.Phase("stats"),
+		logger.Duration(time.Since(startTime)),
+	)
+
+	return totalSize, nil
+}
@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/acearchive/artifact-action/internal/testutil"
+	"github.com/acearchive/artifact-action/parse"
+	"github.com/ipfs/go-cid"
+	"github.com/spf13/viper"
+)
+
+func testArtifact(slug string, day int, mediaTypes ...string) parse.Artifact {
+	files := make([]interface{}, len(mediaTypes))
+
+	for i, mediaType := range mediaTypes {
+		files[i] = testutil.File("", "", mediaType)
+	}
+
+	return testutil.Artifact(slug, testutil.Date(day), parse.GenericEntry{string(parse.FieldFiles): files})
+}
+
+func TestComputeOnlyCountsCurrentArtifacts(t *testing.T) {
+	artifacts := []parse.Artifact{
+		testArtifact("first", 1, "image/png"),
+		testArtifact("first", 2, "image/png", "application/pdf"),
+		testArtifact("the-second", 1),
+		testArtifact("second", 3),
+	}
+
+	current := map[string]struct{}{"first": {}, "second": {}}
+
+	got := Compute(artifacts, current, nil)
+
+	if got.Artifacts != 2 {
+		t.Errorf("Artifacts = %d, want 2", got.Artifacts)
+	}
+
+	if got.Versions != len(artifacts) {
+		t.Errorf("Versions = %d, want %d", got.Versions, len(artifacts))
+	}
+
+	if got.Files != 2 {
+		t.Errorf("Files = %d, want 2", got.Files)
+	}
+
+	if want := []string{"second"}; !reflect.DeepEqual(got.LinkOnly, want) {
+		t.Errorf("LinkOnly = %v, want %v", got.LinkOnly, want)
+	}
+
+	if want := []Count{{Key: "2022-01", Count: 2}}; !reflect.DeepEqual(got.OverTime, want) {
+		t.Errorf("OverTime = %v, want %v", got.OverTime, want)
+	}
+}
+
+func TestTotalSize(t *testing.T) {
+	// Older versions of Kubo report `Size` and newer versions report
+	// `TotalSize`.
+	responses := map[string]string{
+		"bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku": `{"Size": 100, "NumBlocks": 1}`,
+		"bafkreidgvpkjawlxz6sffxzwgooowe5yt7i6wsyg236mfoks77nywkptdq": `{"TotalSize": 250, "DagStats": [{"Size": 250, "NumBlocks": 3}]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/dag/stat" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if progress := r.URL.Query().Get("progress"); progress != "false" {
+			t.Errorf("progress = %q, want false", progress)
+		}
+
+		response, exists := responses[r.URL.Query().Get("arg")]
+		if !exists {
+			t.Errorf("unexpected CID %q", r.URL.Query().Get("arg"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("ipfs-api", fmt.Sprintf("/ip4/%s/tcp/%s", serverURL.Hostname(), serverURL.Port()))
+	defer viper.Set("ipfs-api", "")
+
+	cidList := make([]cid.Cid, 0, len(responses))
+
+	for cidStr := range responses {
+		fileCid, err := cid.Decode(cidStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cidList = append(cidList, fileCid)
+	}
+
+	got, err := TotalSize(context.Background(), cidList)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != 350 {
+		t.Errorf("TotalSize() = %d, want 350", got)
+	}
+}
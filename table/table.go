@@ -0,0 +1,353 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+var (
+	ErrInvalidTable  = errors.New("this is not a valid table")
+	ErrInvalidColumn = errors.New("this is not a valid column for this table")
+)
+
+// listSeparator separates the values of list fields which are joined into a
+// single cell.
+const listSeparator = "; "
+
+// Name is the name of a table.
+type Name string
+
+const (
+	// Artifacts has one row per artifact.
+	Artifacts Name = "artifacts"
+
+	// Files has one row per file in each artifact.
+	Files Name = "files"
+
+	// People has one row per person in each artifact.
+	People Name = "people"
+
+	// Links has one row per link in each artifact.
+	Links Name = "links"
+)
+
+// Type is the Frictionless Table Schema type of the values in a column.
+type Type string
+
+const (
+	TypeString   Type = "string"
+	TypeInteger  Type = "integer"
+	TypeDatetime Type = "datetime"
+)
+
+// Field describes a column of a table.
+type Field struct {
+	Name        string
+	Type        Type
+	Description string
+}
+
+// row is the data a column is computed from. For the files and links tables,
+// `Object` is the file or link the row is for, and for the people table,
+// `Value` is the person the row is for.
+type row struct {
+	Artifact parse.Artifact
+	Object   parse.GenericEntry
+	Value    string
+}
+
+type column struct {
+	Field
+	value func(row row) string
+}
+
+func commitRev(artifact parse.Artifact) string {
+	if artifact.Commit == nil {
+		return ""
+	}
+
+	return artifact.Commit.Rev
+}
+
+func commitDate(artifact parse.Artifact) string {
+	if artifact.Commit == nil {
+		return ""
+	}
+
+	return artifact.Commit.Date.Format("2006-01-02T15:04:05Z07:00")
+}
+
+func optionalInt(entry parse.GenericEntry, field parse.EntryField) string {
+	if value, ok := entry.Int(field); ok {
+		return strconv.Itoa(value)
+	}
+
+	return ""
+}
+
+// Years returns the years an artifact is from, like `1972` or `1995-2003`.
+func Years(entry parse.GenericEntry) string {
+	fromYear, hasFromYear := entry.Int(parse.FieldFromYear)
+	toYear, hasToYear := entry.Int(parse.FieldToYear)
+
+	switch {
+	case hasFromYear && hasToYear && toYear != fromYear:
+		return fmt.Sprintf("%d-%d", fromYear, toYear)
+	case hasFromYear:
+		return strconv.Itoa(fromYear)
+	default:
+		return ""
+	}
+}
+
+func joinInts(values []int) string {
+	strs := make([]string, len(values))
+
+	for i, value := range values {
+		strs[i] = strconv.Itoa(value)
+	}
+
+	return strings.Join(strs, listSeparator)
+}
+
+func newColumn(name string, kind Type, description string, value func(row row) string) column {
+	return column{Field: Field{Name: name, Type: kind, Description: description}, value: value}
+}
+
+func joinStrings(field parse.EntryField) func(row row) string {
+	return func(r row) string { return strings.Join(r.Artifact.Entry.Strings(field), listSeparator) }
+}
+
+var (
+	slugColumn = newColumn("slug", TypeString, "The slug of the artifact", func(r row) string {
+		return r.Artifact.Slug
+	})
+	commitColumn = newColumn("commit", TypeString, "The commit which last modified the artifact file", func(r row) string {
+		return commitRev(r.Artifact)
+	})
+	dateColumn = newColumn("date", TypeDatetime, "The date of the commit which last modified the artifact file", func(r row) string {
+		return commitDate(r.Artifact)
+	})
+)
+
+var artifactColumns = []column{
+	slugColumn,
+	newColumn("path", TypeString, "The path of the artifact file in the repository", func(r row) string {
+		return r.Artifact.Path
+	}),
+	newColumn("version", TypeInteger, "The schema version of the artifact file", func(r row) string {
+		return optionalInt(r.Artifact.Entry, parse.FieldVersion)
+	}),
+	newColumn("title", TypeString, "The title of the artifact without Markdown", func(r row) string {
+		return r.Artifact.TitlePlain
+	}),
+	newColumn("titleHTML", TypeString, "The title of the artifact as an HTML fragment", func(r row) string {
+		return r.Artifact.TitleHTML
+	}),
+	newColumn("sortKey", TypeString, "The key to sort the artifact by its title", func(r row) string {
+		return r.Artifact.SortKey
+	}),
+	newColumn("description", TypeString, "A short description of the artifact", func(r row) string {
+		return strings.TrimSpace(r.Artifact.Entry.String(parse.FieldDescription))
+	}),
+	newColumn("years", TypeString, "The year or range of years the artifact is from", func(r row) string {
+		return Years(r.Artifact.Entry)
+	}),
+	newColumn("fromYear", TypeInteger, "The year the artifact is from, or the first year", func(r row) string {
+		return optionalInt(r.Artifact.Entry, parse.FieldFromYear)
+	}),
+	newColumn("toYear", TypeInteger, "The last year the artifact is from, if it spans several years", func(r row) string {
+		return optionalInt(r.Artifact.Entry, parse.FieldToYear)
+	}),
+	newColumn("decades", TypeString, "The decades the artifact is from, separated by semicolons", func(r row) string {
+		return joinInts(r.Artifact.Entry.Ints(parse.FieldDecades))
+	}),
+	newColumn("people", TypeString, "The people associated with the artifact, separated by semicolons", joinStrings(parse.FieldPeople)),
+	newColumn("identities", TypeString, "The identities the artifact is about, separated by semicolons", joinStrings(parse.FieldIdentities)),
+	newColumn("aliases", TypeString, "The previous slugs of the artifact, separated by semicolons", joinStrings(parse.FieldAliases)),
+	newColumn("files", TypeInteger, "The number of files in the artifact", func(r row) string {
+		return strconv.Itoa(len(r.Artifact.Entry.Objects(parse.FieldFiles)))
+	}),
+	newColumn("links", TypeInteger, "The number of links in the artifact", func(r row) string {
+		return strconv.Itoa(len(r.Artifact.Entry.Objects(parse.FieldLinks)))
+	}),
+	commitColumn,
+	dateColumn,
+}
+
+var fileColumns = []column{
+	slugColumn,
+	newColumn("name", TypeString, "The human-readable name of the file", func(r row) string {
+		return r.Object.String(parse.FieldFileName)
+	}),
+	newColumn("filename", TypeString, "The file name of the file", func(r row) string {
+		return r.Object.String(parse.FieldFileFilename)
+	}),
+	newColumn("mediaType", TypeString, "The media type of the file", func(r row) string {
+		return r.Object.String(parse.FieldFileMediaType)
+	}),
+	newColumn("cid", TypeString, "The IPFS CID of the file", func(r row) string {
+		return r.Object.String(parse.FieldFileCid)
+	}),
+	commitColumn,
+	dateColumn,
+}
+
+var peopleColumns = []column{
+	slugColumn,
+	newColumn("name", TypeString, "The name of the person", func(r row) string {
+		return r.Value
+	}),
+}
+
+var linkColumns = []column{
+	slugColumn,
+	newColumn("name", TypeString, "The human-readable name of the link", func(r row) string {
+		return r.Object.String(parse.FieldLinkName)
+	}),
+	newColumn("url", TypeString, "The URL of the link", func(r row) string {
+		return r.Object.String(parse.FieldLinkURL)
+	}),
+}
+
+var defaultColumns = map[Name][]string{
+	Artifacts: {"slug", "title", "years", "identities", "commit"},
+	Files:     {"slug", "filename", "mediaType", "cid"},
+	People:    {"slug", "name"},
+	Links:     {"slug", "name", "url"},
+}
+
+func columnsOf(table Name) ([]column, error) {
+	switch table {
+	case Artifacts:
+		return artifactColumns, nil
+	case Files:
+		return fileColumns, nil
+	case People:
+		return peopleColumns, nil
+	case Links:
+		return linkColumns, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTable, table)
+	}
+}
+
+// Names returns the names of all the tables.
+func Names() []string {
+	return []string{string(Artifacts), string(Files), string(People), string(Links)}
+}
+
+// Columns returns the names of all the columns in a table.
+func Columns(table Name) []string {
+	columns, _ := columnsOf(table)
+	names := make([]string, len(columns))
+
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+
+	return names
+}
+
+// Fields returns the description of every column in a table.
+func Fields(table Name) []Field {
+	columns, _ := columnsOf(table)
+	fields := make([]Field, len(columns))
+
+	for i, column := range columns {
+		fields[i] = column.Field
+	}
+
+	return fields
+}
+
+// DefaultColumns returns the names of the columns included in a table when
+// none are selected.
+func DefaultColumns(table Name) []string {
+	return defaultColumns[table]
+}
+
+func selectColumns(table Name, names []string) ([]column, error) {
+	columns, err := columnsOf(table)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		names = DefaultColumns(table)
+	}
+
+	selected := make([]column, 0, len(names))
+
+	for _, name := range names {
+		found := false
+
+		for _, column := range columns {
+			if column.Name == name {
+				selected = append(selected, column)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s (expected one of %s)", ErrInvalidColumn, name, strings.Join(Columns(table), ", "))
+		}
+	}
+
+	return selected, nil
+}
+
+// Build returns the header and rows of a table built from `artifacts`,
+// including only the named columns, or the default columns if there are
+// none.
+func Build(table Name, artifacts []parse.Artifact, columnNames []string) ([]string, [][]string, error) {
+	columns, err := selectColumns(table, columnNames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := make([]string, len(columns))
+
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+
+	var rows []row
+
+	for _, artifact := range artifacts {
+		switch table {
+		case Files:
+			for _, file := range artifact.Entry.Objects(parse.FieldFiles) {
+				rows = append(rows, row{Artifact: artifact, Object: file, Value: ""})
+			}
+		case Links:
+			for _, link := range artifact.Entry.Objects(parse.FieldLinks) {
+				rows = append(rows, row{Artifact: artifact, Object: link, Value: ""})
+			}
+		case People:
+			for _, person := range artifact.Entry.Strings(parse.FieldPeople) {
+				rows = append(rows, row{Artifact: artifact, Object: nil, Value: person})
+			}
+		default:
+			rows = append(rows, row{Artifact: artifact, Object: nil, Value: ""})
+		}
+	}
+
+	records := make([][]string, len(rows))
+
+	for rowIndex, currentRow := range rows {
+		records[rowIndex] = make([]string, len(columns))
+
+		for columnIndex, column := range columns {
+			records[rowIndex][columnIndex] = column.value(currentRow)
+		}
+	}
+
+	return header, records, nil
+}
@@ -0,0 +1,85 @@
+package table
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/acearchive/artifact-action/parse"
+)
+
+func TestBuild(t *testing.T) {
+	artifacts := []parse.Artifact{
+		{
+			Slug: "manifesto",
+			Commit: &parse.ArtifactCommit{
+				Rev:  "abc123",
+				Date: time.Date(2022, time.January, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60)),
+			},
+			Entry: parse.GenericEntry{
+				"files": []interface{}{
+					map[string]interface{}{"filename": "scan.pdf", "mediaType": "application/pdf", "cid": "bafy1"},
+					map[string]interface{}{"filename": "transcript.html", "cid": "bafy2"},
+				},
+			},
+		},
+		{Slug: "zine", Commit: nil, Entry: parse.GenericEntry{"files": []interface{}{}}},
+	}
+
+	tests := []struct {
+		name       string
+		table      Name
+		columns    []string
+		wantHeader []string
+		wantRows   [][]string
+	}{
+		{
+			name:       "one row per file",
+			table:      Files,
+			columns:    nil,
+			wantHeader: []string{"slug", "filename", "mediaType", "cid"},
+			wantRows: [][]string{
+				{"manifesto", "scan.pdf", "application/pdf", "bafy1"},
+				{"manifesto", "transcript.html", "", "bafy2"},
+			},
+		},
+		{
+			name:       "selected columns",
+			table:      Artifacts,
+			columns:    []string{"date", "slug", "files"},
+			wantHeader: []string{"date", "slug", "files"},
+			wantRows: [][]string{
+				{"2022-01-01T12:00:00-05:00", "manifesto", "2"},
+				{"", "zine", "0"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header, rows, err := Build(test.table, artifacts, test.columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(header, test.wantHeader) {
+				t.Errorf("header = %v, want %v", header, test.wantHeader)
+			}
+
+			if !reflect.DeepEqual(rows, test.wantRows) {
+				t.Errorf("rows = %v, want %v", rows, test.wantRows)
+			}
+		})
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	if _, _, err := Build("nonexistent", nil, nil); !errors.Is(err, ErrInvalidTable) {
+		t.Errorf("Build() = %v, want %v", err, ErrInvalidTable)
+	}
+
+	if _, _, err := Build(Files, nil, []string{"title"}); !errors.Is(err, ErrInvalidColumn) {
+		t.Errorf("Build() = %v, want %v", err, ErrInvalidColumn)
+	}
+}